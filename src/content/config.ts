@@ -17,6 +17,13 @@ const issues = defineCollection({
     description: z.string(),
     ascii_header: z.string().optional(),
     draft: z.boolean().default(false),
+    // CTF challenges playable from the SSH BBS. Only the flag's SHA-256 is published.
+    challenges: z.array(z.object({
+      id: z.string(),
+      title: z.string().optional(),
+      points: z.number().int().positive(),
+      flag_sha256: z.string().regex(/^[0-9a-fA-F]{64}$/),
+    })).default([]),
   }),
 });
 
@@ -28,4 +35,15 @@ const pages = defineCollection({
   }),
 });
 
-export const collections = { issues, pages };
+// Reader polls, served by the SSH BBS voting booth.
+const polls = defineCollection({
+  type: 'data',
+  schema: z.object({
+    question: z.string(),
+    description: z.string().optional(),
+    options: z.array(z.string()).min(2),
+    closes: z.date().optional(),
+  }),
+});
+
+export const collections = { issues, pages, polls };
@@ -0,0 +1,335 @@
+// Package access decides which addresses may call the board: static
+// allow and deny lists, a cap on concurrent sessions per address, and
+// temporary bans for addresses that keep tripping the abuse checks.
+package access
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrDenied is returned for addresses outside the allow list or inside
+	// the deny list.
+	ErrDenied = errors.New("access denied")
+
+	// ErrTooMany is returned when an address already has its maximum
+	// number of sessions open.
+	ErrTooMany = errors.New("too many sessions from your address")
+)
+
+// BannedError is returned for an address under a temporary ban.
+type BannedError struct {
+	Until time.Time
+}
+
+func (e *BannedError) Error() string {
+	return "temporarily banned until " + e.Until.UTC().Format("2006-01-02 15:04 MST")
+}
+
+// Config holds the access rules.
+type Config struct {
+	Allow    []*net.IPNet  // if non-empty, only these networks may call
+	Deny     []*net.IPNet  // these networks may never call
+	MaxPerIP int           // concurrent sessions per address; 0 is unlimited
+	Strikes  int           // strikes within Window that earn a ban; 0 disables auto-bans
+	Window   time.Duration // how long a strike counts
+	BanFor   time.Duration // length of an automatic ban
+	Track    int           // addresses whose strikes are remembered; 0 is unlimited
+}
+
+// Ban is one temporary ban. Only banned addresses are ever written to
+// disk, and only until the ban expires.
+type Ban struct {
+	IP     string    `json:"ip"`
+	Until  time.Time `json:"until"`
+	Reason string    `json:"reason"`
+}
+
+// Guard applies the access rules and is shared by all sessions.
+type Guard struct {
+	cfg  Config
+	path string
+
+	mu      sync.Mutex
+	bans    map[string]Ban
+	active  map[string]int         // address → open sessions
+	strikes map[string][]time.Time // address → recent strikes
+}
+
+// Open loads the bans stored at path, starting with none if it does not
+// exist.
+func Open(path string, cfg Config) (*Guard, error) {
+	g := &Guard{
+		cfg:     cfg,
+		path:    path,
+		bans:    make(map[string]Ban),
+		active:  make(map[string]int),
+		strikes: make(map[string][]time.Time),
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return g, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var bans []Ban
+	if err := json.Unmarshal(data, &bans); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	for _, b := range bans {
+		if b.Until.After(now) {
+			g.bans[b.IP] = b
+		}
+	}
+	return g, nil
+}
+
+// ParseCIDRs parses a comma-separated list of networks. Bare addresses
+// are taken as single-host networks.
+func ParseCIDRs(s string) ([]*net.IPNet, error) {
+	var out []*net.IPNet
+	for _, f := range strings.Split(s, ",") {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+		if !strings.Contains(f, "/") {
+			ip := net.ParseIP(f)
+			if ip == nil {
+				return nil, fmt.Errorf("bad address %q", f)
+			}
+			bits := 128
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 32
+			}
+			out = append(out, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, n, err := net.ParseCIDR(f)
+		if err != nil {
+			return nil, fmt.Errorf("bad network %q", f)
+		}
+		out = append(out, n)
+	}
+	return out, nil
+}
+
+// IP extracts the address from a connection's remote address.
+func IP(addr net.Addr) net.IP {
+	switch a := addr.(type) {
+	case *net.TCPAddr:
+		return a.IP
+	case nil:
+		return nil
+	}
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		host = addr.String()
+	}
+	return net.ParseIP(host)
+}
+
+// key is the map key for ip, normalized so IPv4 and IPv4-in-IPv6 match.
+func key(ip net.IP) string {
+	if v4 := ip.To4(); v4 != nil {
+		return v4.String()
+	}
+	return ip.String()
+}
+
+func contains(nets []*net.IPNet, ip net.IP) bool {
+	for _, n := range nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// Check reports whether ip may connect at all. Connections without an IP
+// address (unix sockets) are always let through.
+func (g *Guard) Check(ip net.IP) error {
+	if ip == nil {
+		return nil
+	}
+	if contains(g.cfg.Deny, ip) || (len(g.cfg.Allow) > 0 && !contains(g.cfg.Allow, ip)) {
+		return ErrDenied
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	b, ok := g.bans[key(ip)]
+	if !ok {
+		return nil
+	}
+	if time.Now().Before(b.Until) {
+		return &BannedError{Until: b.Until}
+	}
+	delete(g.bans, key(ip))
+	return nil
+}
+
+// Acquire takes one of ip's session slots. The returned func gives it
+// back and must be called when the session ends.
+func (g *Guard) Acquire(ip net.IP) (release func(), err error) {
+	if ip == nil || g.cfg.MaxPerIP <= 0 {
+		return func() {}, nil
+	}
+	k := key(ip)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.active[k] >= g.cfg.MaxPerIP {
+		return nil, ErrTooMany
+	}
+	g.active[k]++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			g.mu.Lock()
+			defer g.mu.Unlock()
+			if g.active[k]--; g.active[k] <= 0 {
+				delete(g.active, k)
+			}
+		})
+	}, nil
+}
+
+// Strike records abusive behaviour from ip and bans it once it has
+// collected enough strikes within the window. It reports whether this
+// strike caused a ban.
+func (g *Guard) Strike(ip net.IP, reason string) (bool, error) {
+	if ip == nil || g.cfg.Strikes <= 0 {
+		return false, nil
+	}
+	k := key(ip)
+	now := time.Now()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	recent := g.strikes[k][:0]
+	for _, t := range g.strikes[k] {
+		if now.Sub(t) < g.cfg.Window {
+			recent = append(recent, t)
+		}
+	}
+	recent = append(recent, now)
+	if len(recent) < g.cfg.Strikes {
+		if _, ok := g.strikes[k]; !ok {
+			g.makeRoom(now)
+		}
+		g.strikes[k] = recent
+		return false, nil
+	}
+
+	delete(g.strikes, k)
+	g.bans[k] = Ban{IP: k, Until: now.Add(g.cfg.BanFor), Reason: reason}
+	return true, g.save()
+}
+
+// makeRoom keeps the strike table within Track before a new address is
+// added: expired strikes go first, then the least recently struck
+// address. Caller must hold g.mu.
+func (g *Guard) makeRoom(now time.Time) {
+	if g.cfg.Track <= 0 || len(g.strikes) < g.cfg.Track {
+		return
+	}
+	var oldest string
+	for k, ts := range g.strikes {
+		last := ts[len(ts)-1]
+		if now.Sub(last) >= g.cfg.Window {
+			delete(g.strikes, k)
+			continue
+		}
+		if oldest == "" || last.Before(g.strikes[oldest][len(g.strikes[oldest])-1]) {
+			oldest = k
+		}
+	}
+	if len(g.strikes) >= g.cfg.Track {
+		delete(g.strikes, oldest)
+	}
+}
+
+// Ban bans the address ip for d.
+func (g *Guard) Ban(ip string, d time.Duration, reason string) error {
+	parsed := net.ParseIP(strings.TrimSpace(ip))
+	if parsed == nil {
+		return fmt.Errorf("bad address %q", ip)
+	}
+	if d <= 0 {
+		d = g.cfg.BanFor
+	}
+	k := key(parsed)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.bans[k] = Ban{IP: k, Until: time.Now().Add(d), Reason: reason}
+	return g.save()
+}
+
+// Unban lifts the ban on ip, reporting whether there was one.
+func (g *Guard) Unban(ip string) (bool, error) {
+	parsed := net.ParseIP(strings.TrimSpace(ip))
+	if parsed == nil {
+		return false, fmt.Errorf("bad address %q", ip)
+	}
+	k := key(parsed)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if _, ok := g.bans[k]; !ok {
+		return false, nil
+	}
+	delete(g.bans, k)
+	return true, g.save()
+}
+
+// Bans returns the bans still in force, soonest to expire first.
+func (g *Guard) Bans() []Ban {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.activeBans()
+}
+
+// activeBans lists unexpired bans. Caller must hold g.mu.
+func (g *Guard) activeBans() []Ban {
+	now := time.Now()
+	out := make([]Ban, 0, len(g.bans))
+	for _, b := range g.bans {
+		if b.Until.After(now) {
+			out = append(out, b)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Until.Before(out[j].Until) })
+	return out
+}
+
+// save writes the active bans atomically. Caller must hold g.mu.
+func (g *Guard) save() error {
+	data, err := json.MarshalIndent(g.activeBans(), "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(g.path), 0o755); err != nil {
+		return err
+	}
+	tmp := g.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, g.path)
+}
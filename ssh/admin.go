@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/charmbracelet/ssh"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// adminCommand is the SSH command that opens the sysop console.
+const adminCommand = "admin"
+
+// adminKeys is the set of public keys allowed into the sysop console.
+type adminKeys map[string]bool
+
+// loadAdminKeys reads an OpenSSH authorized_keys file. An empty path
+// disables the console.
+func loadAdminKeys(path string) (adminKeys, error) {
+	keys := make(adminKeys)
+	if path == "" {
+		return keys, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	for len(bytes.TrimSpace(data)) > 0 {
+		var pk gossh.PublicKey
+		pk, _, _, data, err = gossh.ParseAuthorizedKey(data)
+		if err != nil {
+			// ParseAuthorizedKey skips comments and blank lines itself, so
+			// an error here means nothing usable is left.
+			if len(keys) == 0 {
+				return nil, fmt.Errorf("%s: %w", path, err)
+			}
+			break
+		}
+		keys[gossh.FingerprintSHA256(pk)] = true
+	}
+	if len(keys) == 0 {
+		return nil, errors.New(path + ": no keys found")
+	}
+	return keys, nil
+}
+
+// wantsAdmin reports whether the session asked for the sysop console.
+func wantsAdmin(sess ssh.Session) bool {
+	cmd := sess.Command()
+	return len(cmd) == 1 && cmd[0] == adminCommand
+}
+
+// allowed reports whether sess authenticated with a sysop key.
+func (k adminKeys) allowed(sess ssh.Session) bool {
+	id := keyID(sess)
+	return id != "" && k[id]
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/ssh"
+	"github.com/charmbracelet/wish/bubbletea"
+
+	"terminull-ssh/nodes"
+	"terminull-ssh/ui"
+	"terminull-ssh/ui/layout"
+	"terminull-ssh/ui/types"
+)
+
+// waitForNode shows the busy screen to a caller who found every node
+// taken. With queue set the caller waits in line, watching their place
+// update, and gets the first node that frees up; otherwise they are
+// hung up on. It returns nil if the caller never got a node.
+func waitForNode(sess ssh.Session, reg *nodes.Registry, handle string, member bool, ip string, queue bool) *nodes.Node {
+	pty, windowChanges, ok := sess.Pty()
+	if !ok {
+		return nil
+	}
+
+	var ticket *nodes.Ticket
+	position := 0
+	if queue {
+		ticket = reg.Enqueue(member)
+		defer reg.Dequeue(ticket)
+		position = reg.Position(ticket)
+	}
+
+	w, h := layout.Fit(pty.Window.Width, pty.Window.Height)
+	if w == 0 || h == 0 {
+		w, h = 80, 24
+	}
+	model := ui.NewBusy(reg.Max(), reg.Reserved(), member, queue, position, w, h)
+	p := tea.NewProgram(model, append([]tea.ProgramOption{tea.WithAltScreen(), tea.WithoutSignalHandler()}, bubbletea.MakeOptions(sess)...)...)
+
+	ctx, cancel := context.WithCancel(sess.Context())
+	watcher := make(chan *nodes.Node, 1)
+	go func() {
+		var got *nodes.Node
+		defer func() { watcher <- got }()
+		down := false
+		for {
+			changed := reg.Changed()
+			if !down && reg.Draining() {
+				down = true
+				go p.Send(types.ShutdownMsg{})
+			} else if ticket != nil && !down {
+				if n, ok := reg.TryJoin(ticket, handle, ip); ok {
+					got = n
+					p.Send(types.NodeReadyMsg{})
+					return
+				}
+				go p.Send(types.QueueMsg{Position: reg.Position(ticket)})
+			}
+			select {
+			case <-ctx.Done():
+				p.Quit()
+				return
+			case win := <-windowChanges:
+				go p.Send(tea.WindowSizeMsg{Width: win.Width, Height: win.Height})
+			case <-changed:
+			}
+		}
+	}()
+
+	p.Run()
+	// Stop the watcher before handing the window changes on to the board.
+	cancel()
+	got := <-watcher
+	if got != nil && !model.Ready() {
+		// The caller hung up just as their node came free.
+		reg.Leave(got)
+		return nil
+	}
+	return got
+}
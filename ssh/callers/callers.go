@@ -0,0 +1,136 @@
+package callers
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// capacity is the size of the persisted ring of calls.
+const capacity = 100
+
+// Call is one finished session. Remote addresses are never recorded.
+type Call struct {
+	Handle    string        `json:"handle"`
+	Node      int           `json:"node"`
+	Connected time.Time     `json:"connected"`
+	Duration  time.Duration `json:"duration"`
+	Member    bool          `json:"member"`
+}
+
+// logFile is the on-disk layout of the callers log.
+type logFile struct {
+	Calls  []Call   `json:"calls"`
+	OptOut []string `json:"opt_out"` // key fingerprints that asked not to be listed
+}
+
+// Log is the persisted ring of recent calls, shared by all sessions.
+type Log struct {
+	path string
+
+	mu     sync.Mutex
+	calls  []Call
+	optOut map[string]bool
+}
+
+// Open loads the callers log stored at path, starting empty if it does
+// not exist.
+func Open(path string) (*Log, error) {
+	l := &Log{path: path, optOut: make(map[string]bool)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return l, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var f logFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, err
+	}
+	l.calls = f.Calls
+	for _, k := range f.OptOut {
+		l.optOut[k] = true
+	}
+	return l, nil
+}
+
+// Record appends a finished call, dropping the oldest once full.
+func (l *Log) Record(c Call) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.calls = append(l.calls, c)
+	if len(l.calls) > capacity {
+		l.calls = l.calls[len(l.calls)-capacity:]
+	}
+	return l.save()
+}
+
+// Recent returns up to n of the newest calls, newest first.
+func (l *Log) Recent(n int) []Call {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var out []Call
+	for i := len(l.calls) - 1; i >= 0 && len(out) < n; i-- {
+		out = append(out, l.calls[i])
+	}
+	return out
+}
+
+// OptedOut reports whether the member with keyID asked not to be listed.
+func (l *Log) OptedOut(keyID string) bool {
+	if keyID == "" {
+		return false
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.optOut[keyID]
+}
+
+// SetOptOut remembers a member's listing preference across sessions.
+func (l *Log) SetOptOut(keyID string, v bool) error {
+	if keyID == "" {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if v {
+		l.optOut[keyID] = true
+	} else {
+		delete(l.optOut, keyID)
+	}
+	return l.save()
+}
+
+// save writes the log atomically. Caller must hold l.mu.
+func (l *Log) save() error {
+	f := logFile{Calls: l.calls}
+	for k := range l.optOut {
+		f.OptOut = append(f.OptOut, k)
+	}
+	sort.Strings(f.OptOut)
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o755); err != nil {
+		return err
+	}
+	tmp := l.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, l.path)
+}
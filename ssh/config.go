@@ -1,52 +1,426 @@
 package main
 
 import (
+	"errors"
 	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
 	"os"
+	"reflect"
 	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"terminull-ssh/access"
 )
 
-// Config holds runtime configuration from env vars and flags.
+// Config holds runtime configuration. Values come from the built-in
+// defaults, then the YAML config file, then TERMINULL_* env vars, then
+// flags, each layer overriding the one before.
 type Config struct {
-	Host        string
-	Port        int
-	ContentDir  string
-	SiteURL     string
-	HostKeyPath string
+	Host         string        `yaml:"host"`
+	Port         int           `yaml:"port"`
+	Listen       string        `yaml:"listen"`     // comma-separated listen addresses, unix:/path for a socket; overrides host and port
+	ProxyFrom    string        `yaml:"proxy_from"` // comma-separated proxies trusted to send PROXY protocol headers; "unix" trusts unix sockets
+	ContentDir   string        `yaml:"content_dir"`
+	SiteURL      string        `yaml:"site_url"`
+	HostKeys     string        `yaml:"host_keys"`          // comma-separated host key files offered to clients
+	NextHostKeys string        `yaml:"next_host_keys"`     // keys being rotated in: announced to clients but not yet offered
+	HostKey      string        `yaml:"host_key,omitempty"` // deprecated: the single key setting host_keys replaced, taken as its first entry
+	DoorsFile    string        `yaml:"doors"`
+	DataDir      string        `yaml:"data_dir"`
+	AdminKeys    string        `yaml:"admin_keys"`     // authorized_keys file of sysops allowed into the admin console
+	ControlSock  string        `yaml:"control_socket"` // local unix socket for sysop commands
+	Drain        time.Duration `yaml:"drain"`          // how long callers get to finish up on shutdown
+	MetricsAddr  string        `yaml:"metrics_addr"`   // HTTP address for Prometheus metrics
+	LogLevel     string        `yaml:"log_level"`      // debug, info, warn or error
+	LogFormat    string        `yaml:"log_format"`     // text or json
+	LogIP        string        `yaml:"log_ip"`         // full, truncate or none
+	Allow        string        `yaml:"allow"`          // comma-separated networks allowed to call; empty allows all
+	Deny         string        `yaml:"deny"`           // comma-separated networks never allowed to call
+	MaxPerIP     int           `yaml:"max_per_ip"`     // concurrent sessions per address; 0 is unlimited
+	BanStrikes   int           `yaml:"ban_strikes"`    // rejections within BanWindow that earn a ban; 0 disables auto-bans
+	BanWindow    time.Duration `yaml:"ban_window"`
+	BanFor       time.Duration `yaml:"ban_for"`
+	MaxNodes     int           `yaml:"max_nodes"`      // concurrent callers; 0 is unlimited
+	Reserved     int           `yaml:"reserved_nodes"` // nodes kept for members
+	Queue        bool          `yaml:"queue"`          // let callers wait in line when all nodes are busy
+
+	IdleTimeout time.Duration `yaml:"idle_timeout"` // hang up after this long without input; 0 disables
+	MaxTimeout  time.Duration `yaml:"max_timeout"`  // hang up after this long regardless; 0 disables
+
+	RateInterval time.Duration `yaml:"rate_interval"`  // sustained rate: one new connection per interval per address
+	RateBurst    int           `yaml:"rate_burst"`     // connections allowed in a burst
+	RateTrack    int           `yaml:"rate_track_ips"` // addresses the rate limiter and strike counter remember
+
+	ContentWidth int `yaml:"content_width"` // widest column content is laid out in
+	MinCols      int `yaml:"min_cols"`      // smaller terminals are asked to enlarge, larger ones drawn at the max
+	MaxCols      int `yaml:"max_cols"`
+	MinRows      int `yaml:"min_rows"`
+	MaxRows      int `yaml:"max_rows"`
+	RenderCache  int `yaml:"render_cache"` // rendered articles kept for all sessions to share; 0 disables
+
+	RenderTimeout time.Duration `yaml:"render_timeout"`  // longest a document may take to render; 0 is unlimited
+	RenderMaxSize int           `yaml:"render_max_size"` // largest a rendered document may be, in bytes; 0 is unlimited
+
+	RecordDir  string `yaml:"record_dir"`  // record sessions as asciicast files here; empty disables
+	RecordKeep int    `yaml:"record_keep"` // newest recordings kept
+
+	File        string `yaml:"-"` // config file the values were read from
+	PrintConfig bool   `yaml:"-"`
 }
 
-// LoadConfig reads env vars with flag overrides.
-func LoadConfig() Config {
-	cfg := Config{
-		Host:        envOr("TERMINULL_HOST", "0.0.0.0"),
-		Port:        envInt("TERMINULL_PORT", 2222),
-		ContentDir:  envOr("TERMINULL_CONTENT_DIR", "../src/content"),
-		SiteURL:     envOr("TERMINULL_SITE_URL", "https://terminull.local"),
-		HostKeyPath: envOr("TERMINULL_HOST_KEY", "./ssh_host_ed25519_key"),
+// defaultConfig returns the built-in defaults.
+func defaultConfig() Config {
+	return Config{
+		Host:       "0.0.0.0",
+		Port:       2222,
+		ContentDir: "../src/content",
+		SiteURL:    "https://terminull.local",
+		HostKeys:   "./ssh_host_ed25519_key,./ssh_host_ecdsa_key,./ssh_host_rsa_key",
+		DataDir:    "./data",
+		Drain:      60 * time.Second,
+		LogLevel:   "info",
+		LogFormat:  "text",
+		LogIP:      logIPTruncate,
+		MaxPerIP:   3,
+		BanStrikes: 5,
+		BanWindow:  10 * time.Minute,
+		BanFor:     time.Hour,
+
+		IdleTimeout: 10 * time.Minute,
+		MaxTimeout:  2 * time.Hour,
+
+		// 1 conn/sec sustained, burst of 10, track up to 256 IPs
+		RateInterval: time.Second,
+		RateBurst:    10,
+		RateTrack:    256,
+
+		ContentWidth: 78,
+		MinCols:      40,
+		MaxCols:      300,
+		MinRows:      10,
+		MaxRows:      100,
+		RenderCache:  256,
+
+		RenderTimeout: 2 * time.Second,
+		RenderMaxSize: 4 << 20,
+
+		RecordKeep: 50,
 	}
+}
+
+// LoadConfig layers the config file, env vars and flags over the defaults
+// and validates the result.
+func LoadConfig() (Config, error) {
+	cfg := defaultConfig()
 
+	flag.StringVar(&cfg.File, "config", os.Getenv("TERMINULL_CONFIG"), "YAML config file")
+	flag.BoolVar(&cfg.PrintConfig, "print-config", false, "print the effective configuration as YAML and exit")
 	flag.StringVar(&cfg.Host, "host", cfg.Host, "bind host")
 	flag.IntVar(&cfg.Port, "port", cfg.Port, "bind port")
+	flag.StringVar(&cfg.Listen, "listen", cfg.Listen, "comma-separated listen addresses, e.g. 0.0.0.0:2222,[::]:2222,unix:/run/terminull.sock (overrides --host and --port)")
+	flag.StringVar(&cfg.ProxyFrom, "proxy-from", cfg.ProxyFrom, "comma-separated CIDRs of proxies sending PROXY protocol headers; \"unix\" trusts unix sockets (empty disables)")
 	flag.StringVar(&cfg.ContentDir, "content-dir", cfg.ContentDir, "path to content directory")
 	flag.StringVar(&cfg.SiteURL, "site-url", cfg.SiteURL, "public site URL")
-	flag.StringVar(&cfg.HostKeyPath, "host-key", cfg.HostKeyPath, "SSH host key path")
+	flag.StringVar(&cfg.HostKeys, "host-keys", cfg.HostKeys, "comma-separated SSH host key paths, at most one per key type (generated if missing)")
+	flag.StringVar(&cfg.NextHostKeys, "next-host-keys", cfg.NextHostKeys, "comma-separated host keys to announce to clients ahead of a rotation")
+	flag.StringVar(&cfg.HostKey, "host-key", cfg.HostKey, "deprecated: SSH host key path, used as the first --host-keys entry")
+	flag.StringVar(&cfg.DoorsFile, "doors", cfg.DoorsFile, "path to doors YAML file (empty disables doors)")
+	flag.StringVar(&cfg.DataDir, "data-dir", cfg.DataDir, "directory for persisted BBS state")
+	flag.StringVar(&cfg.AdminKeys, "admin-keys", cfg.AdminKeys, "authorized_keys file for the sysop console (empty disables it)")
+	flag.StringVar(&cfg.ControlSock, "control-socket", cfg.ControlSock, "unix socket for sysop commands (empty disables it)")
+	flag.DurationVar(&cfg.Drain, "drain", cfg.Drain, "how long to let sessions finish on shutdown (0 hangs up at once)")
+	flag.StringVar(&cfg.MetricsAddr, "metrics-addr", cfg.MetricsAddr, "address for the Prometheus /metrics endpoint, e.g. 127.0.0.1:9222 (empty disables it)")
+	flag.StringVar(&cfg.LogLevel, "log-level", cfg.LogLevel, "log level: debug, info, warn or error")
+	flag.StringVar(&cfg.LogFormat, "log-format", cfg.LogFormat, "log format: text or json")
+	flag.StringVar(&cfg.LogIP, "log-ip", cfg.LogIP, "how to log caller addresses: full, truncate (network only) or none")
+	flag.StringVar(&cfg.Allow, "allow", cfg.Allow, "comma-separated CIDRs allowed to connect (empty allows all)")
+	flag.StringVar(&cfg.Deny, "deny", cfg.Deny, "comma-separated CIDRs refused outright")
+	flag.IntVar(&cfg.MaxPerIP, "max-per-ip", cfg.MaxPerIP, "concurrent sessions per address (0 = unlimited)")
+	flag.IntVar(&cfg.BanStrikes, "ban-strikes", cfg.BanStrikes, "rate-limit or username rejections that earn a temporary ban (0 disables)")
+	flag.DurationVar(&cfg.BanWindow, "ban-window", cfg.BanWindow, "how long a rejection counts towards a ban")
+	flag.DurationVar(&cfg.BanFor, "ban-for", cfg.BanFor, "length of an automatic ban")
+	flag.IntVar(&cfg.MaxNodes, "max-nodes", cfg.MaxNodes, "maximum concurrent callers (0 = unlimited)")
+	flag.IntVar(&cfg.Reserved, "reserved-nodes", cfg.Reserved, "nodes kept for callers with an SSH key")
+	flag.BoolVar(&cfg.Queue, "queue", cfg.Queue, "let callers wait in line when all nodes are busy")
+	flag.DurationVar(&cfg.IdleTimeout, "idle-timeout", cfg.IdleTimeout, "hang up on callers idle this long (0 disables)")
+	flag.DurationVar(&cfg.MaxTimeout, "max-timeout", cfg.MaxTimeout, "hang up on every caller after this long (0 disables)")
+	flag.DurationVar(&cfg.RateInterval, "rate-interval", cfg.RateInterval, "sustained connection rate per address: one per interval")
+	flag.IntVar(&cfg.RateBurst, "rate-burst", cfg.RateBurst, "connections per address allowed in a burst")
+	flag.IntVar(&cfg.RateTrack, "rate-track-ips", cfg.RateTrack, "addresses the rate limiter and strike counter remember")
+	flag.IntVar(&cfg.ContentWidth, "content-width", cfg.ContentWidth, "widest column content is laid out in")
+	flag.IntVar(&cfg.MinCols, "min-cols", cfg.MinCols, "narrowest terminal drawn in; narrower ones are asked to enlarge (at least 40)")
+	flag.IntVar(&cfg.MaxCols, "max-cols", cfg.MaxCols, "widest terminal laid out for; wider ones are drawn at this width")
+	flag.IntVar(&cfg.MinRows, "min-rows", cfg.MinRows, "shortest terminal drawn in; shorter ones are asked to enlarge (at least 10)")
+	flag.IntVar(&cfg.MaxRows, "max-rows", cfg.MaxRows, "tallest terminal laid out for; taller ones are drawn at this height")
+	flag.IntVar(&cfg.RenderCache, "render-cache", cfg.RenderCache, "rendered articles kept in memory for all sessions (0 disables)")
+	flag.DurationVar(&cfg.RenderTimeout, "render-timeout", cfg.RenderTimeout, "show documents taking longer than this to render as plain text (0 is unlimited)")
+	flag.IntVar(&cfg.RenderMaxSize, "render-max-size", cfg.RenderMaxSize, "show documents rendering to more bytes than this as plain text (0 is unlimited)")
+	flag.StringVar(&cfg.RecordDir, "record-dir", cfg.RecordDir, "record every session as an asciicast v2 file in this directory (empty disables)")
+	flag.IntVar(&cfg.RecordKeep, "record-keep", cfg.RecordKeep, "number of recordings to keep; older ones are deleted")
 	flag.Parse()
 
-	return cfg
+	// Flags are bound to cfg, so remember the ones given on the command
+	// line, rebuild cfg from the lower layers, then apply them again.
+	given := make(map[string]string)
+	flag.Visit(func(f *flag.Flag) { given[f.Name] = f.Value.String() })
+
+	file, printConfig := cfg.File, cfg.PrintConfig
+	cfg = defaultConfig()
+	cfg.File, cfg.PrintConfig = file, printConfig
+
+	if cfg.File != "" {
+		if err := cfg.readFile(cfg.File); err != nil {
+			return cfg, err
+		}
+	}
+	if err := cfg.readEnv(); err != nil {
+		return cfg, err
+	}
+	for name, v := range given {
+		if err := flag.Set(name, v); err != nil {
+			return cfg, fmt.Errorf("-%s: %w", name, err)
+		}
+	}
+	cfg.applyHostKey()
+
+	return cfg, cfg.Validate()
+}
+
+// applyHostKey puts the deprecated single host key in place of the first
+// host_keys entry, so a deployment still setting it keeps offering the
+// key its callers know rather than generating a new one.
+func (c *Config) applyHostKey() {
+	if c.HostKey == "" {
+		return
+	}
+	keys := splitList(c.HostKeys)
+	for _, k := range keys {
+		if k == c.HostKey {
+			return
+		}
+	}
+	if len(keys) == 0 {
+		keys = []string{c.HostKey}
+	} else {
+		keys[0] = c.HostKey
+	}
+	c.HostKeys = strings.Join(keys, ",")
 }
 
-func envOr(key, fallback string) string {
+// readFile overlays the YAML file at path. Unknown keys are errors so
+// typos do not go unnoticed.
+func (c *Config) readFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("config file: %w", err)
+	}
+	defer f.Close()
+
+	dec := yaml.NewDecoder(f)
+	dec.KnownFields(true)
+	if err := dec.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// readEnv overlays the TERMINULL_* env vars that are set.
+func (c *Config) readEnv() error {
+	var e envReader
+	e.str("TERMINULL_HOST", &c.Host)
+	e.int("TERMINULL_PORT", &c.Port)
+	e.str("TERMINULL_LISTEN", &c.Listen)
+	e.str("TERMINULL_PROXY_FROM", &c.ProxyFrom)
+	e.str("TERMINULL_CONTENT_DIR", &c.ContentDir)
+	e.str("TERMINULL_SITE_URL", &c.SiteURL)
+	e.str("TERMINULL_HOST_KEYS", &c.HostKeys)
+	e.str("TERMINULL_NEXT_HOST_KEYS", &c.NextHostKeys)
+	e.str("TERMINULL_HOST_KEY", &c.HostKey)
+	e.str("TERMINULL_DOORS", &c.DoorsFile)
+	e.str("TERMINULL_DATA_DIR", &c.DataDir)
+	e.str("TERMINULL_ADMIN_KEYS", &c.AdminKeys)
+	e.str("TERMINULL_CONTROL_SOCKET", &c.ControlSock)
+	e.duration("TERMINULL_DRAIN", &c.Drain)
+	e.str("TERMINULL_METRICS_ADDR", &c.MetricsAddr)
+	e.str("TERMINULL_LOG_LEVEL", &c.LogLevel)
+	e.str("TERMINULL_LOG_FORMAT", &c.LogFormat)
+	e.str("TERMINULL_LOG_IP", &c.LogIP)
+	e.str("TERMINULL_ALLOW", &c.Allow)
+	e.str("TERMINULL_DENY", &c.Deny)
+	e.int("TERMINULL_MAX_PER_IP", &c.MaxPerIP)
+	e.int("TERMINULL_BAN_STRIKES", &c.BanStrikes)
+	e.duration("TERMINULL_BAN_WINDOW", &c.BanWindow)
+	e.duration("TERMINULL_BAN_FOR", &c.BanFor)
+	e.int("TERMINULL_MAX_NODES", &c.MaxNodes)
+	e.int("TERMINULL_RESERVED_NODES", &c.Reserved)
+	e.bool("TERMINULL_QUEUE", &c.Queue)
+	e.duration("TERMINULL_IDLE_TIMEOUT", &c.IdleTimeout)
+	e.duration("TERMINULL_MAX_TIMEOUT", &c.MaxTimeout)
+	e.duration("TERMINULL_RATE_INTERVAL", &c.RateInterval)
+	e.int("TERMINULL_RATE_BURST", &c.RateBurst)
+	e.int("TERMINULL_RATE_TRACK_IPS", &c.RateTrack)
+	e.int("TERMINULL_CONTENT_WIDTH", &c.ContentWidth)
+	e.int("TERMINULL_MIN_COLS", &c.MinCols)
+	e.int("TERMINULL_MAX_COLS", &c.MaxCols)
+	e.int("TERMINULL_MIN_ROWS", &c.MinRows)
+	e.int("TERMINULL_MAX_ROWS", &c.MaxRows)
+	e.int("TERMINULL_RENDER_CACHE", &c.RenderCache)
+	e.duration("TERMINULL_RENDER_TIMEOUT", &c.RenderTimeout)
+	e.int("TERMINULL_RENDER_MAX_SIZE", &c.RenderMaxSize)
+	e.str("TERMINULL_RECORD_DIR", &c.RecordDir)
+	e.int("TERMINULL_RECORD_KEEP", &c.RecordKeep)
+	return errors.Join(e.errs...)
+}
+
+// Validate checks the configuration, reporting every problem at once.
+func (c Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.Port > 0 && c.Port < 65536, "port: %d is not a valid port", c.Port)
+	for _, addr := range splitList(c.Listen) {
+		if err := checkListen(addr); err != nil {
+			errs = append(errs, fmt.Errorf("listen: %q: %w", addr, err))
+		}
+	}
+	if _, _, err := parseProxyFrom(c.ProxyFrom); err != nil {
+		errs = append(errs, fmt.Errorf("proxy_from: %w", err))
+	}
+	check(c.ContentDir != "", "content_dir: must be set")
+	check(len(splitList(c.HostKeys)) > 0, "host_keys: must list at least one key")
+	check(c.DataDir != "", "data_dir: must be set")
+	if u, err := url.Parse(c.SiteURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		errs = append(errs, fmt.Errorf("site_url: %q is not an http(s) URL", c.SiteURL))
+	}
+	check(c.Drain >= 0, "drain: must not be negative")
+
+	var level slog.Level
+	check(level.UnmarshalText([]byte(c.LogLevel)) == nil, "log_level: %q: want debug, info, warn or error", c.LogLevel)
+	check(c.LogFormat == "text" || c.LogFormat == "json", "log_format: %q: want text or json", c.LogFormat)
+	if err := checkLogIP(c.LogIP); err != nil {
+		errs = append(errs, fmt.Errorf("log_ip: %w", err))
+	}
+
+	if _, err := access.ParseCIDRs(c.Allow); err != nil {
+		errs = append(errs, fmt.Errorf("allow: %w", err))
+	}
+	if _, err := access.ParseCIDRs(c.Deny); err != nil {
+		errs = append(errs, fmt.Errorf("deny: %w", err))
+	}
+	check(c.MaxPerIP >= 0, "max_per_ip: must not be negative")
+	check(c.BanStrikes >= 0, "ban_strikes: must not be negative")
+	check(c.BanStrikes == 0 || c.BanWindow > 0, "ban_window: must be positive when ban_strikes is set")
+	check(c.BanFor > 0, "ban_for: must be positive")
+
+	check(c.MaxNodes >= 0, "max_nodes: must not be negative")
+	check(c.Reserved >= 0, "reserved_nodes: must not be negative")
+	check(c.Reserved == 0 || c.Reserved < c.MaxNodes,
+		"reserved_nodes: %d must be fewer than max_nodes (%d)", c.Reserved, c.MaxNodes)
+
+	check(c.IdleTimeout >= 0, "idle_timeout: must not be negative")
+	check(c.MaxTimeout >= 0, "max_timeout: must not be negative")
+
+	check(c.RateInterval > 0, "rate_interval: must be positive")
+	check(c.RateBurst > 0, "rate_burst: must be at least 1")
+	check(c.RateTrack > 0, "rate_track_ips: must be at least 1")
+
+	check(c.MinCols >= 40, "min_cols: %d is too narrow (at least 40)", c.MinCols)
+	check(c.MaxCols >= c.MinCols, "max_cols: %d is less than min_cols (%d)", c.MaxCols, c.MinCols)
+	check(c.MinRows >= 10, "min_rows: %d is too short (at least 10)", c.MinRows)
+	check(c.MaxRows >= c.MinRows, "max_rows: %d is less than min_rows (%d)", c.MaxRows, c.MinRows)
+	check(c.ContentWidth >= 20, "content_width: %d is too narrow (at least 20)", c.ContentWidth)
+	check(c.RenderCache >= 0, "render_cache: must not be negative")
+	check(c.RenderTimeout >= 0, "render_timeout: must not be negative")
+	check(c.RenderMaxSize >= 0, "render_max_size: must not be negative")
+
+	check(c.RecordKeep > 0, "record_keep: must be at least 1")
+
+	return errors.Join(errs...)
+}
+
+// YAML renders c as a config file. Durations are written the way they
+// are read, e.g. "10m0s", rather than as nanoseconds.
+func (c Config) YAML() ([]byte, error) {
+	doc := &yaml.Node{Kind: yaml.MappingNode}
+	v := reflect.ValueOf(c)
+	for i := 0; i < v.NumField(); i++ {
+		key, opts, _ := strings.Cut(v.Type().Field(i).Tag.Get("yaml"), ",")
+		if key == "" || key == "-" || (opts == "omitempty" && v.Field(i).IsZero()) {
+			continue
+		}
+		val := &yaml.Node{}
+		if d, ok := v.Field(i).Interface().(time.Duration); ok {
+			val.SetString(d.String())
+		} else if err := val.Encode(v.Field(i).Interface()); err != nil {
+			return nil, err
+		}
+		doc.Content = append(doc.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, val)
+	}
+	return yaml.Marshal(doc)
+}
+
+// splitList splits a comma-separated setting, dropping empty entries.
+func splitList(s string) []string {
+	var out []string
+	for _, f := range strings.Split(s, ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// envReader overlays env vars onto config fields, collecting any that do
+// not parse.
+type envReader struct {
+	errs []error
+}
+
+func (e *envReader) str(key string, dst *string) {
 	if v := os.Getenv(key); v != "" {
-		return v
+		*dst = v
+	}
+}
+
+func (e *envReader) int(key string, dst *int) {
+	if v := os.Getenv(key); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			e.errs = append(e.errs, fmt.Errorf("%s: %q is not a number", key, v))
+			return
+		}
+		*dst = n
+	}
+}
+
+func (e *envReader) duration(key string, dst *time.Duration) {
+	if v := os.Getenv(key); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			e.errs = append(e.errs, fmt.Errorf("%s: %q is not a duration", key, v))
+			return
+		}
+		*dst = d
 	}
-	return fallback
 }
 
-func envInt(key string, fallback int) int {
+func (e *envReader) bool(key string, dst *bool) {
 	if v := os.Getenv(key); v != "" {
-		if n, err := strconv.Atoi(v); err == nil {
-			return n
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			e.errs = append(e.errs, fmt.Errorf("%s: %q is not true or false", key, v))
+			return
 		}
+		*dst = b
 	}
-	return fallback
 }
@@ -0,0 +1,44 @@
+package content
+
+import "strings"
+
+// CodeBlock is a fenced code block in a markdown document.
+type CodeBlock struct {
+	Lang string // the info string's first word, e.g. "python", or ""
+	Code string // the block's text exactly as written, without the fences
+}
+
+// CodeBlocks returns the fenced code blocks of a markdown document in
+// order. A block left open runs to the end of the document.
+func CodeBlocks(md string) []CodeBlock {
+	var out []CodeBlock
+	var code []string
+	fence, indent, lang := "", 0, ""
+	for _, line := range strings.Split(md, "\n") {
+		m := fenceRegex.FindStringSubmatch(line)
+		switch {
+		case fence == "" && m != nil:
+			fence, lang = m[1], ""
+			indent = len(line) - len(strings.TrimLeft(line, " "))
+			if f := strings.Fields(strings.TrimLeft(line[indent:], "`~")); len(f) > 0 {
+				lang = f[0]
+			}
+			code = code[:0]
+		case fence == "":
+		case m != nil && m[1] == fence && strings.Trim(line, " "+fence[:1]) == "":
+			out = append(out, CodeBlock{Lang: lang, Code: strings.Join(code, "\n")})
+			fence = ""
+		default:
+			// Lines lose as much indentation as the opening fence had.
+			n := 0
+			for n < indent && n < len(line) && line[n] == ' ' {
+				n++
+			}
+			code = append(code, line[n:])
+		}
+	}
+	if fence != "" {
+		out = append(out, CodeBlock{Lang: lang, Code: strings.Join(code, "\n")})
+	}
+	return out
+}
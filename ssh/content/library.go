@@ -0,0 +1,41 @@
+package content
+
+import (
+	"log/slog"
+	"sync"
+	"sync/atomic"
+)
+
+// Library holds the live Store and can swap in a freshly loaded one
+// without disturbing sessions still reading the old one.
+type Library struct {
+	dir    string
+	log    *slog.Logger
+	checks []Check
+
+	mu  sync.Mutex // serializes reloads
+	cur atomic.Pointer[Store]
+}
+
+// NewLibrary loads the content in dir, reporting problems to logger. The
+// checks are run over every load.
+func NewLibrary(dir string, logger *slog.Logger, checks ...Check) *Library {
+	l := &Library{dir: dir, log: logger, checks: checks}
+	l.cur.Store(LoadStore(dir, logger, checks...))
+	return l
+}
+
+// Store returns the most recently loaded content.
+func (l *Library) Store() *Store {
+	return l.cur.Load()
+}
+
+// Reload rescans the content directory and publishes the result. Sessions
+// pick it up the next time they open a screen.
+func (l *Library) Reload() *Store {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	s := LoadStore(l.dir, l.log, l.checks...)
+	l.cur.Store(s)
+	return s
+}
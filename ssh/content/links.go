@@ -0,0 +1,180 @@
+package content
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var (
+	// linkRegex matches inline code, which is left alone, an image, also
+	// left alone, a [text](url "title") link, or a <url> autolink.
+	linkRegex = regexp.MustCompile("(`+)[^`]*`+" +
+		`|!\[[^\]]*\]\([^)]*\)` +
+		`|\[([^\]]*)\]\(\s*<?([^)\s>]+)>?(?:\s+"[^"]*")?\s*\)` +
+		`|<((?:https?|mailto):[^>\s]+)>`)
+)
+
+// Link is a link in a markdown document.
+type Link struct {
+	Text string
+	URL  string
+}
+
+// NumberLinks replaces each link in a markdown document with its text and
+// a reference number, [1] for the first, and returns the links in order.
+// Links in code and images are left as they are.
+func NumberLinks(md string) (string, []Link) {
+	var links []Link
+	out := mapOutsideFences(md, func(line string) string {
+		return linkRegex.ReplaceAllStringFunc(line, func(m string) string {
+			sub := linkRegex.FindStringSubmatch(m)
+			var l Link
+			switch {
+			case sub[3] != "":
+				l = Link{Text: strings.TrimSpace(markupRegex.ReplaceAllString(sub[2], "")), URL: sub[3]}
+			case sub[4] != "":
+				l = Link{Text: sub[4], URL: sub[4]}
+			default:
+				return m // code or an image
+			}
+			if l.Text == "" {
+				l.Text = l.URL
+			}
+			links = append(links, l)
+			return fmt.Sprintf(`%s \[%d\]`, sub[2]+sub[4], len(links))
+		})
+	})
+	return out, links
+}
+
+// Links returns the links in a markdown document in order.
+func Links(md string) []Link {
+	_, links := NumberLinks(md)
+	return links
+}
+
+// mapOutsideFences applies fn to every line of md that is not part of a
+// fenced code block.
+func mapOutsideFences(md string, fn func(string) string) string {
+	lines := strings.Split(md, "\n")
+	fence := ""
+	for i, line := range lines {
+		if m := fenceRegex.FindStringSubmatch(line); m != nil {
+			switch fence {
+			case "":
+				fence = m[1]
+			case m[1]:
+				fence = ""
+			}
+			continue
+		}
+		if fence == "" {
+			lines[i] = fn(line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// LinkKind says where a link leads.
+type LinkKind int
+
+const (
+	LinkExternal LinkKind = iota // another site, or a file on this one
+	LinkInternal                 // something the BBS can show
+	LinkBroken                   // a page of this site that does not exist
+)
+
+// Target is what an internal link shows.
+type Target struct {
+	Screen  string // "home", "volume", "article", "page", "help" or "section"
+	Volume  int
+	Article int    // index in the volume
+	Page    string // page slug
+	Anchor  string // heading slug, for a link to a section of the same page
+}
+
+// Resolve works out where url leads. Links to the site are those starting
+// with / or siteURL; the site's article, volume and page URLs lead to the
+// same things in the BBS, and other files on the site are external.
+func (s *Store) Resolve(url, siteURL string) (Target, LinkKind) {
+	if anchor, ok := strings.CutPrefix(url, "#"); ok {
+		return Target{Screen: "section", Anchor: anchor}, LinkInternal
+	}
+	path, ok := "", false
+	if siteURL != "" {
+		path, ok = strings.CutPrefix(url, strings.TrimSuffix(siteURL, "/"))
+		ok = ok && (path == "" || strings.HasPrefix(path, "/") || strings.HasPrefix(path, "#"))
+	}
+	if !ok {
+		if !strings.HasPrefix(url, "/") || strings.HasPrefix(url, "//") {
+			return Target{}, LinkExternal
+		}
+		path = url
+	}
+	if i := strings.IndexAny(path, "?#"); i >= 0 {
+		path = path[:i]
+	}
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".txt")
+
+	var seg []string
+	if path != "" {
+		seg = strings.Split(path, "/")
+	}
+	switch {
+	case len(seg) == 0 || len(seg) == 1 && seg[0] == "vol":
+		return Target{Screen: "home"}, LinkInternal
+	case seg[0] == "vol":
+		n, err := strconv.Atoi(seg[1])
+		vol := s.volume(n)
+		if err != nil || vol == nil || len(seg) > 3 {
+			return Target{}, LinkBroken
+		}
+		if len(seg) == 2 {
+			return Target{Screen: "volume", Volume: n}, LinkInternal
+		}
+		for i, a := range vol.Articles {
+			if a.Slug == seg[2] {
+				return Target{Screen: "article", Volume: n, Article: i}, LinkInternal
+			}
+		}
+		return Target{}, LinkBroken
+	case len(seg) > 1 || strings.Contains(seg[0], "."):
+		return Target{}, LinkExternal // media and other files
+	case seg[0] == "help":
+		return Target{Screen: "help"}, LinkInternal
+	}
+	for _, p := range s.Pages {
+		if p.Slug == seg[0] {
+			return Target{Screen: "page", Page: p.Slug}, LinkInternal
+		}
+	}
+	return Target{}, LinkBroken
+}
+
+// volume returns volume n, or nil if there is none.
+func (s *Store) volume(n int) *Volume {
+	for i := range s.Volumes {
+		if s.Volumes[i].Number == n {
+			return &s.Volumes[i]
+		}
+	}
+	return nil
+}
+
+// HeadingSlug returns the anchor a heading gets on the site: lowercase,
+// spaces as hyphens, and other punctuation dropped.
+func HeadingSlug(text string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_':
+			b.WriteRune(r)
+		case r == ' ':
+			b.WriteByte('-')
+		}
+	}
+	return b.String()
+}
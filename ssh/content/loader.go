@@ -2,6 +2,7 @@ package content
 
 import (
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -11,6 +12,8 @@ import (
 	"time"
 
 	"gopkg.in/yaml.v3"
+
+	"terminull-ssh/metrics"
 )
 
 // articleFrontmatter matches the Astro content schema for issues.
@@ -25,6 +28,16 @@ type articleFrontmatter struct {
 	Tags        []string `yaml:"tags"`
 	Description string   `yaml:"description"`
 	Draft       bool     `yaml:"draft"`
+
+	Challenges []challengeFrontmatter `yaml:"challenges"`
+}
+
+// challengeFrontmatter is one entry of an article's challenges list.
+type challengeFrontmatter struct {
+	ID         string `yaml:"id"`
+	Title      string `yaml:"title"`
+	Points     int    `yaml:"points"`
+	FlagSHA256 string `yaml:"flag_sha256"`
 }
 
 // pageFrontmatter matches the Astro content schema for pages.
@@ -33,12 +46,23 @@ type pageFrontmatter struct {
 	Description string `yaml:"description"`
 }
 
+// pollFile matches the Astro content schema for polls.
+type pollFile struct {
+	Question    string   `yaml:"question"`
+	Description string   `yaml:"description"`
+	Options     []string `yaml:"options"`
+	Closes      string   `yaml:"closes"`
+}
+
 // maxFileSize is the maximum markdown file size we'll read (1 MB).
 const maxFileSize = 1 << 20
 
 // volDirRegex matches "vol1", "vol2", etc.
 var volDirRegex = regexp.MustCompile(`^vol(\d+)$`)
 
+// sha256HexRegex matches a hex-encoded SHA-256 digest.
+var sha256HexRegex = regexp.MustCompile(`^[0-9a-fA-F]{64}$`)
+
 // isInsideDir checks that child is a descendant of parent after resolving symlinks.
 func isInsideDir(child, parent string) bool {
 	resolvedChild, err := filepath.EvalSymlinks(child)
@@ -70,24 +94,40 @@ func safeReadFile(path, baseDir string) ([]byte, error) {
 	return os.ReadFile(path)
 }
 
+// warnf logs a content problem and keeps it on the store so the admin
+// console can show it later. File names in it are sanitized like content.
+func (store *Store) warnf(format string, args ...any) {
+	msg := SanitizeLine(fmt.Sprintf(format, args...))
+	store.log.Warn(msg)
+	store.Warnings = append(store.Warnings, msg)
+}
+
+// Check looks over freshly loaded content for problems the loader cannot
+// see for itself, such as articles too costly to render, and returns a
+// warning for each.
+type Check func(*Store) []string
+
 // LoadStore scans contentDir for issues and pages, returns a populated Store.
-func LoadStore(contentDir string) *Store {
-	store := &Store{}
+// Problems with individual files are logged to logger and skipped, and
+// problems the checks find are added to the warnings.
+func LoadStore(contentDir string, logger *slog.Logger, checks ...Check) *Store {
+	store := &Store{LoadedAt: time.Now(), log: logger}
 
 	// Resolve the content directory to an absolute path for symlink checks
 	absContentDir, err := filepath.Abs(contentDir)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "warn: cannot resolve content dir %s: %v\n", contentDir, err)
+		store.warnf("cannot resolve content dir %s: %v", contentDir, err)
 		return store
 	}
 
 	// Load issues
 	issuesDir := filepath.Join(absContentDir, "issues")
 	volumeMap := make(map[int][]Article)
+	seenChallenges := make(map[string]string) // challenge ID → article slug
 
 	entries, err := os.ReadDir(issuesDir)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "warn: cannot read issues dir %s: %v\n", issuesDir, err)
+		store.warnf("cannot read issues dir %s: %v", issuesDir, err)
 	} else {
 		for _, entry := range entries {
 			if !entry.IsDir() {
@@ -99,9 +139,10 @@ func LoadStore(contentDir string) *Store {
 			}
 			volNum, _ := strconv.Atoi(match[1])
 			volDir := filepath.Join(issuesDir, entry.Name())
-			articles := loadArticlesFromDir(volDir, volNum, absContentDir)
+			articles := store.loadArticlesFromDir(volDir, volNum, absContentDir)
 			for _, a := range articles {
 				if !a.Draft {
+					a.Challenges = store.dropDuplicateChallenges(a, seenChallenges)
 					volumeMap[volNum] = append(volumeMap[volNum], a)
 					store.Articles = append(store.Articles, a)
 				}
@@ -131,20 +172,38 @@ func LoadStore(contentDir string) *Store {
 
 	// Load pages
 	pagesDir := filepath.Join(absContentDir, "pages")
-	store.Pages = loadPages(pagesDir, absContentDir)
+	store.Pages = store.loadPages(pagesDir, absContentDir)
+
+	// Load polls (optional)
+	pollsDir := filepath.Join(absContentDir, "polls")
+	store.Polls = store.loadPolls(pollsDir, absContentDir)
+
+	store.checkLinks()
+	for _, check := range checks {
+		for _, w := range check(store) {
+			store.warnf("%s", w)
+		}
+	}
 
-	fmt.Fprintf(os.Stderr, "content: loaded %d volumes, %d articles, %d pages\n",
-		len(store.Volumes), len(store.Articles), len(store.Pages))
+	metrics.ContentLoads.Inc()
+	metrics.ContentItems.WithLabelValues("volumes").Set(float64(len(store.Volumes)))
+	metrics.ContentItems.WithLabelValues("articles").Set(float64(len(store.Articles)))
+	metrics.ContentItems.WithLabelValues("pages").Set(float64(len(store.Pages)))
+	metrics.ContentItems.WithLabelValues("polls").Set(float64(len(store.Polls)))
+	metrics.ContentWarnings.Set(float64(len(store.Warnings)))
+
+	logger.Info("content loaded", "volumes", len(store.Volumes), "articles", len(store.Articles),
+		"pages", len(store.Pages), "polls", len(store.Polls), "warnings", len(store.Warnings))
 
 	return store
 }
 
-func loadArticlesFromDir(dir string, defaultVolume int, baseDir string) []Article {
+func (store *Store) loadArticlesFromDir(dir string, defaultVolume int, baseDir string) []Article {
 	var articles []Article
 
 	entries, err := os.ReadDir(dir)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "warn: cannot read dir %s: %v\n", dir, err)
+		store.warnf("cannot read dir %s: %v", dir, err)
 		return nil
 	}
 
@@ -160,19 +219,19 @@ func loadArticlesFromDir(dir string, defaultVolume int, baseDir string) []Articl
 		filePath := filepath.Join(dir, name)
 		data, err := safeReadFile(filePath, baseDir)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "warn: skipping %s: %v\n", name, err)
+			store.warnf("skipping %s: %v", name, err)
 			continue
 		}
 
 		fm, body, err := splitFrontmatter(string(data))
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "warn: bad frontmatter in %s: %v\n", name, err)
+			store.warnf("bad frontmatter in %s: %v", name, err)
 			continue
 		}
 
 		var meta articleFrontmatter
 		if err := yaml.Unmarshal([]byte(fm), &meta); err != nil {
-			fmt.Fprintf(os.Stderr, "warn: cannot parse frontmatter in %s: %v\n", name, err)
+			store.warnf("cannot parse frontmatter in %s: %v", name, err)
 			continue
 		}
 
@@ -187,7 +246,7 @@ func loadArticlesFromDir(dir string, defaultVolume int, baseDir string) []Articl
 			vol = defaultVolume
 		}
 
-		articles = append(articles, Article{
+		a := Article{
 			Title:       meta.Title,
 			Author:      meta.Author,
 			Handle:      meta.Handle,
@@ -200,18 +259,61 @@ func loadArticlesFromDir(dir string, defaultVolume int, baseDir string) []Articl
 			Draft:       meta.Draft,
 			Slug:        slug,
 			Body:        body,
-		})
+			Challenges:  store.parseChallenges(meta.Challenges, name),
+		}
+		if a.sanitize() {
+			store.warnf("removed terminal control sequences from %s", name)
+		}
+		articles = append(articles, a)
 	}
 
 	return articles
 }
 
-func loadPages(dir string, baseDir string) []Page {
+// parseChallenges validates an article's challenges, warning about and
+// skipping malformed entries.
+func (store *Store) parseChallenges(raw []challengeFrontmatter, name string) []Challenge {
+	var out []Challenge
+	for _, c := range raw {
+		if c.ID == "" || c.Points <= 0 || !sha256HexRegex.MatchString(c.FlagSHA256) {
+			store.warnf("skipping challenge %q in %s: needs id, positive points and a sha256 flag hash", c.ID, name)
+			continue
+		}
+		title := c.Title
+		if title == "" {
+			title = c.ID
+		}
+		out = append(out, Challenge{
+			ID:         c.ID,
+			Title:      title,
+			Points:     c.Points,
+			FlagSHA256: strings.ToLower(c.FlagSHA256),
+		})
+	}
+	return out
+}
+
+// dropDuplicateChallenges returns a's challenges minus any whose ID was
+// already seen, since solves are recorded by challenge ID.
+func (store *Store) dropDuplicateChallenges(a Article, seen map[string]string) []Challenge {
+	var kept []Challenge
+	for _, c := range a.Challenges {
+		if first, ok := seen[c.ID]; ok {
+			store.warnf("duplicate challenge %q in %s (first defined in %s)", c.ID, a.Slug, first)
+			continue
+		}
+		seen[c.ID] = a.Slug
+		kept = append(kept, c)
+	}
+	return kept
+}
+
+func (store *Store) loadPages(dir string, baseDir string) []Page {
 	var pages []Page
 
 	entries, err := os.ReadDir(dir)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "warn: cannot read pages dir %s: %v\n", dir, err)
+		store.warnf("cannot read pages dir %s: %v", dir, err)
 		return nil
 	}
 
@@ -224,7 +326,7 @@ func loadPages(dir string, baseDir string) []Page {
 		filePath := filepath.Join(dir, name)
 		data, err := safeReadFile(filePath, baseDir)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "warn: skipping page %s: %v\n", name, err)
+			store.warnf("skipping page %s: %v", name, err)
 			continue
 		}
 
@@ -240,17 +342,99 @@ func loadPages(dir string, baseDir string) []Page {
 
 		slug := strings.TrimSuffix(name, filepath.Ext(name))
 
-		pages = append(pages, Page{
+		p := Page{
 			Title:       meta.Title,
 			Description: meta.Description,
 			Slug:        slug,
 			Body:        body,
-		})
+		}
+		if p.sanitize() {
+			store.warnf("removed terminal control sequences from page %s", name)
+		}
+		pages = append(pages, p)
 	}
 
 	return pages
 }
 
+func (store *Store) loadPolls(dir string, baseDir string) []Poll {
+	var polls []Poll
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			store.warnf("cannot read polls dir %s: %v", dir, err)
+		}
+		return nil
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, "_") ||
+			(!strings.HasSuffix(name, ".yaml") && !strings.HasSuffix(name, ".yml")) {
+			continue
+		}
+
+		data, err := safeReadFile(filepath.Join(dir, name), baseDir)
+		if err != nil {
+			store.warnf("skipping poll %s: %v", name, err)
+			continue
+		}
+
+		var meta pollFile
+		if err := yaml.Unmarshal(data, &meta); err != nil {
+			store.warnf("cannot parse poll %s: %v", name, err)
+			continue
+		}
+		if meta.Question == "" || len(meta.Options) < 2 {
+			store.warnf("skipping poll %s: needs a question and at least 2 options", name)
+			continue
+		}
+
+		var closes time.Time
+		if meta.Closes != "" {
+			closes, err = time.Parse("2006-01-02", meta.Closes)
+			if err != nil {
+				store.warnf("bad closes date in poll %s: %v", name, err)
+				continue
+			}
+		}
+
+		p := Poll{
+			ID:          strings.TrimSuffix(name, filepath.Ext(name)),
+			Question:    meta.Question,
+			Description: meta.Description,
+			Options:     meta.Options,
+			Closes:      closes,
+		}
+		if p.sanitize() {
+			store.warnf("removed terminal control sequences from poll %s", name)
+		}
+		// Votes are counted by option text.
+		if i := duplicate(p.Options); i >= 0 {
+			store.warnf("skipping poll %s: option %q is listed twice", name, p.Options[i])
+			continue
+		}
+		polls = append(polls, p)
+	}
+
+	sort.Slice(polls, func(i, j int) bool { return polls[i].ID < polls[j].ID })
+	return polls
+}
+
+// duplicate returns the index of the first string in ss that appears
+// earlier in ss, or -1 if none does.
+func duplicate(ss []string) int {
+	seen := make(map[string]bool, len(ss))
+	for i, s := range ss {
+		if seen[s] {
+			return i
+		}
+		seen[s] = true
+	}
+	return -1
+}
+
 // splitFrontmatter splits a markdown file at the --- fences.
 func splitFrontmatter(content string) (frontmatter, body string, err error) {
 	const fence = "---"
@@ -281,3 +465,26 @@ func splitFrontmatter(content string) (frontmatter, body string, err error) {
 
 	return frontmatter, body, nil
 }
+
+// checkLinks warns about links to pages of the site that do not exist and
+// to sections a document does not have.
+func (store *Store) checkLinks() {
+	check := func(name, body string) {
+		anchors := make(map[string]bool)
+		for _, h := range Outline(body) {
+			anchors[HeadingSlug(h.Text)] = true
+		}
+		for _, l := range Links(body) {
+			t, kind := store.Resolve(l.URL, "")
+			if kind == LinkBroken || t.Screen == "section" && !anchors[t.Anchor] {
+				store.warnf("broken link %s in %s", l.URL, name)
+			}
+		}
+	}
+	for _, a := range store.Articles {
+		check(fmt.Sprintf("vol%d/%s", a.Volume, a.Slug), a.Body)
+	}
+	for _, p := range store.Pages {
+		check(p.Slug, p.Body)
+	}
+}
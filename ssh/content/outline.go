@@ -0,0 +1,59 @@
+package content
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// headingRegex matches an ATX heading: ## Title, with optional closing #s.
+	headingRegex = regexp.MustCompile(`^ {0,3}(#{1,6})[ \t]+(.*?)(?:[ \t]+#+)?[ \t]*$`)
+
+	// fenceRegex matches the opening or closing line of a fenced code block.
+	fenceRegex = regexp.MustCompile("^ {0,3}(```|~~~)")
+
+	// inlineLinkRegex matches [text](url), keeping text.
+	inlineLinkRegex = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+
+	// markupRegex matches inline markup shown only as styling. Single * and
+	// _ are left alone since they are as likely to be part of a name.
+	markupRegex = regexp.MustCompile("(\\*\\*|~~|`)")
+)
+
+// Heading is one heading in a markdown document.
+type Heading struct {
+	Level int    // 1 for #, 2 for ##, and so on
+	Text  string // the heading as displayed, without markdown markup
+}
+
+// Outline returns the headings of a markdown document in order. Lines in
+// fenced code blocks are not headings, however they look.
+func Outline(md string) []Heading {
+	var out []Heading
+	fence := ""
+	for _, line := range strings.Split(md, "\n") {
+		if m := fenceRegex.FindStringSubmatch(line); m != nil {
+			switch fence {
+			case "":
+				fence = m[1]
+			case m[1]:
+				fence = ""
+			}
+			continue
+		}
+		if fence != "" {
+			continue
+		}
+		m := headingRegex.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		text := inlineLinkRegex.ReplaceAllString(m[2], "$1")
+		text = strings.TrimSpace(markupRegex.ReplaceAllString(text, ""))
+		if text == "" {
+			continue
+		}
+		out = append(out, Heading{Level: len(m[1]), Text: text})
+	}
+	return out
+}
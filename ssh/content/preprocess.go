@@ -22,7 +22,7 @@ func PreprocessMarkdown(md string, siteURL string, volume int, slug string) stri
 	// Admonitions: > [!TYPE] text → > **[!] TYPE:** text
 	result := admonitionRegex.ReplaceAllString(md, `${1}**[!] ${2}:** ${3}`)
 
-	articleURL := siteURL + "/vol/" + itoa(volume) + "/" + slug
+	articleURL := ArticleURL(siteURL, volume, slug)
 
 	// Images → placeholder
 	result = imageRegex.ReplaceAllStringFunc(result, func(match string) string {
@@ -43,6 +43,11 @@ func PreprocessMarkdown(md string, siteURL string, volume int, slug string) stri
 	return result
 }
 
+// ArticleURL returns the address of an article on the site.
+func ArticleURL(siteURL string, volume int, slug string) string {
+	return siteURL + "/vol/" + itoa(volume) + "/" + slug
+}
+
 func itoa(n int) string {
 	if n < 0 {
 		return "-" + uitoa(uint(-n))
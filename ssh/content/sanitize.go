@@ -0,0 +1,215 @@
+package content
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Content goes straight to callers' terminals, so terminal control
+// sequences in it, whether put there by mistake or to change a caller's
+// window title, write to their clipboard or move the cursor, are removed
+// when it is loaded. The only ones kept are colors and styles (SGR) in
+// fenced code blocks marked ```ansi, for ANSI art.
+
+// artLang is the info string marking a fenced code block as ANSI art.
+const artLang = "ansi"
+
+// Sanitize removes terminal control sequences and control characters
+// from s, keeping newlines and tabs. Bytes that are not UTF-8 are dropped
+// too, since terminals may read them as 8-bit controls.
+func Sanitize(s string) string {
+	return strip(s, false)
+}
+
+// SanitizeLine is Sanitize for one-line fields such as titles: newlines
+// and tabs become spaces.
+func SanitizeLine(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\t' {
+			return ' '
+		}
+		return r
+	}, strip(s, false))
+}
+
+// SanitizeMarkdown is Sanitize for a markdown document, except that SGR
+// sequences are kept in ANSI art blocks.
+func SanitizeMarkdown(md string) string {
+	lines := strings.Split(md, "\n")
+	fence, art := "", false
+	for i, line := range lines {
+		plain := strip(line, false)
+		m := fenceRegex.FindStringSubmatch(plain)
+		switch {
+		case fence == "" && m != nil:
+			fence = m[1]
+			info := strings.Fields(strings.TrimLeft(strings.TrimSpace(plain), "`~"))
+			art = len(info) > 0 && info[0] == artLang
+		case fence != "" && m != nil && m[1] == fence && strings.Trim(plain, " "+fence[:1]) == "":
+			fence, art = "", false
+		case art:
+			lines[i] = strip(line, true)
+			continue
+		}
+		lines[i] = plain
+	}
+	return strings.Join(lines, "\n")
+}
+
+// sanitize strips control sequences from every text field of a,
+// reporting whether there were any.
+func (a *Article) sanitize() bool {
+	before := *a
+	a.Title = SanitizeLine(a.Title)
+	a.Author = SanitizeLine(a.Author)
+	a.Handle = SanitizeLine(a.Handle)
+	a.Description = SanitizeLine(a.Description)
+	a.Category = SanitizeLine(a.Category)
+	a.Slug = SanitizeLine(a.Slug)
+	a.Body = SanitizeMarkdown(a.Body)
+	changed := a.Title != before.Title || a.Author != before.Author || a.Handle != before.Handle ||
+		a.Description != before.Description || a.Category != before.Category ||
+		a.Slug != before.Slug || a.Body != before.Body
+
+	a.Tags = append([]string(nil), a.Tags...)
+	for i, t := range a.Tags {
+		a.Tags[i] = SanitizeLine(t)
+		changed = changed || a.Tags[i] != t
+	}
+	a.Challenges = append([]Challenge(nil), a.Challenges...)
+	for i, c := range a.Challenges {
+		a.Challenges[i].ID = SanitizeLine(c.ID)
+		a.Challenges[i].Title = SanitizeLine(c.Title)
+		changed = changed || a.Challenges[i] != c
+	}
+	return changed
+}
+
+// sanitize strips control sequences from every text field of p,
+// reporting whether there were any.
+func (p *Page) sanitize() bool {
+	before := *p
+	p.Title = SanitizeLine(p.Title)
+	p.Description = SanitizeLine(p.Description)
+	p.Slug = SanitizeLine(p.Slug)
+	p.Body = SanitizeMarkdown(p.Body)
+	return *p != before
+}
+
+// sanitize strips control sequences from every text field of p,
+// reporting whether there were any.
+func (p *Poll) sanitize() bool {
+	before := *p
+	p.ID = SanitizeLine(p.ID)
+	p.Question = SanitizeLine(p.Question)
+	p.Description = Sanitize(p.Description)
+	changed := p.ID != before.ID || p.Question != before.Question || p.Description != before.Description
+
+	p.Options = append([]string(nil), p.Options...)
+	for i, o := range p.Options {
+		p.Options[i] = SanitizeLine(o)
+		changed = changed || p.Options[i] != o
+	}
+	return changed
+}
+
+// strip removes control sequences and control characters other than
+// newline and tab from s, keeping SGR sequences if keepSGR is set.
+func strip(s string, keepSGR bool) string {
+	s = strings.ToValidUTF8(s, "")
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == 0x1b || (r >= 0x80 && r <= 0x9f):
+			n := controlLen(s[i:])
+			if keepSGR && isSGR(s[i:i+n]) {
+				b.WriteString(s[i : i+n])
+			}
+			i += n
+			continue
+		case r == '\n' || r == '\t':
+		case r < 0x20 || r == 0x7f:
+			i += size
+			continue
+		}
+		b.WriteString(s[i : i+size])
+		i += size
+	}
+	return b.String()
+}
+
+// controlLen returns the length of the control sequence s starts with,
+// introduced by ESC or by a C1 control character (U+0080 to U+009F), which
+// terminals may take for ESC and the byte 0x40 below it.
+//
+// A sequence is parsed as terminals parse it, so nothing a terminal would
+// act on is left behind. Strings (OSC, DCS, SOS, PM and APC) run to BEL or
+// ST, or until another sequence or CAN or SUB cuts them short; one never
+// ended loses only its introducer, and the rest is left as text.
+func controlLen(s string) int {
+	var kind byte
+	n := 2
+	switch {
+	case s[0] == 0x1b && len(s) < 2:
+		return 1
+	case s[0] == 0x1b:
+		kind = s[1]
+	default:
+		kind = s[1] - 0x40 // C1 characters are two bytes in UTF-8: 0xC2 and 0x80 to 0x9F
+	}
+
+	switch {
+	case kind == '[': // CSI: parameters, intermediates, then a final byte
+		j := n
+		for j < len(s) && s[j] >= 0x30 && s[j] <= 0x3f {
+			j++
+		}
+		for j < len(s) && s[j] >= 0x20 && s[j] <= 0x2f {
+			j++
+		}
+		if j < len(s) && s[j] >= 0x40 && s[j] <= 0x7e {
+			j++
+		}
+		return j
+	case kind == ']' || kind == 'P' || kind == 'X' || kind == '^' || kind == '_':
+		for j := n; j < len(s); j++ {
+			switch {
+			case s[j] == 0x07: // BEL
+				return j + 1
+			case s[j] == 0x1b && j+1 < len(s) && s[j+1] == '\\': // ST
+				return j + 2
+			case s[j] == 0xc2 && j+1 < len(s) && s[j+1] == 0x9c: // ST as a C1 character
+				return j + 2
+			case s[j] == 0x1b || s[j] == 0x18 || s[j] == 0x1a || (s[j] == 0xc2 && j+1 < len(s) && s[j+1] >= 0x80 && s[j+1] <= 0x9f):
+				return j
+			}
+		}
+		return n
+	case kind >= 0x20 && kind <= 0x2f && s[0] == 0x1b: // e.g. ESC ( B: intermediates, then a final byte
+		j := n
+		for j < len(s) && s[j] >= 0x20 && s[j] <= 0x2f {
+			j++
+		}
+		if j < len(s) && s[j] >= 0x30 && s[j] <= 0x7e {
+			j++
+		}
+		return j
+	case kind >= 0x30 && kind <= 0x7e:
+		return n
+	}
+	return 1 // ESC before something that is not a sequence
+}
+
+// isSGR reports whether seq sets colors or styles and nothing else.
+func isSGR(seq string) bool {
+	if len(seq) < 3 || seq[0] != 0x1b || seq[1] != '[' || seq[len(seq)-1] != 'm' {
+		return false
+	}
+	for i := 2; i < len(seq)-1; i++ {
+		if c := seq[i]; (c < '0' || c > '9') && c != ';' && c != ':' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,151 @@
+package content
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		{"plain text", "hello, world", "hello, world"},
+		{"newlines and tabs kept", "a\tb\nc", "a\tb\nc"},
+		{"unicode kept", "héllo ▓▒░ 日本", "héllo ▓▒░ 日本"},
+
+		{"OSC 52 ended by BEL", "a\x1b]52;c;aGVsbG8=\x07b", "ab"},
+		{"OSC 52 ended by ST", "a\x1b]52;c;aGVsbG8=\x1b\\b", "ab"},
+		{"OSC 52 ended by C1 ST", "a\x1b]52;c;aGVsbG8=\u009cb", "ab"},
+		{"OSC 8 keeps the link text", "\x1b]8;;https://evil.example\x1b\\click\x1b]8;;\x1b\\", "click"},
+		{"OSC 0 window title", "\x1b]0;pwned\x07title", "title"},
+		{"OSC cut short by another sequence", "a\x1b]0;x\x1b[31mb", "ab"},
+		{"OSC cut short by CAN", "a\x1b]0;x\x18b", "ab"},
+		{"OSC never ended", "a\x1b]52;c;aGk=", "a52;c;aGk="},
+
+		{"DCS", "a\x1bP1$qm\x1b\\b", "ab"},
+		{"DCS sixel", "a\x1bPq#0;2;0;0;0#0~~\x1b\\b", "ab"},
+		{"APC, PM and SOS", "\x1b_x\x1b\\a\x1b^y\x1b\\b\x1bXz\x1b\\c", "abc"},
+
+		{"CSI color", "\x1b[1;31mred\x1b[0m", "red"},
+		{"CSI cursor and erase", "a\x1b[2J\x1b[H\x1b[10;20Hb\x1b[?25l", "ab"},
+		{"CSI with intermediates", "a\x1b[0 qb", "ab"},
+		{"CSI never ended", "a\x1b[12", "a"},
+		{"ESC charset", "a\x1b(0b\x1b(Bc", "abc"},
+		{"ESC two bytes", "a\x1b7b\x1b8c\x1bcd", "abcd"},
+		{"lone ESC", "a\x1b", "a"},
+
+		{"C1 CSI", "a\u009b31mb", "ab"},
+		{"C1 OSC 52", "a\u009d52;c;aGk=\u009cb", "ab"},
+		{"C1 DCS", "a\u00901$qm\u009cb", "ab"},
+		{"C1 NEL and others", "a\u0085b\u0084c\u008dd", "abcd"},
+
+		{"C0 controls", "a\x07b\x08c\x00d\x7fe", "abcde"},
+		{"carriage returns", "a\r\nb\rc", "a\nbc"},
+		{"invalid UTF-8", "a\x9b31mb\xffc", "a31mbc"},
+	}
+	for _, tt := range tests {
+		if got := Sanitize(tt.in); got != tt.want {
+			t.Errorf("%s: Sanitize(%q) = %q, want %q", tt.name, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSanitizeLine(t *testing.T) {
+	in := "Title\x1b]0;pwned\x07\nsecond\tline"
+	if got, want := SanitizeLine(in), "Title second line"; got != want {
+		t.Errorf("SanitizeLine(%q) = %q, want %q", in, got, want)
+	}
+}
+
+func TestSanitizeMarkdown(t *testing.T) {
+	in := strings.Join([]string{
+		"\x1b[31mnot art\x1b[0m",
+		"```ansi",
+		"\x1b[1;31mRED\x1b[0m \x1b[38;5;82mGREEN\x1b[0m\x1b]52;c;aGk=\x07\x1b[2J\x1b[H",
+		"```",
+		"```",
+		"\x1b[31mcode\x1b[0m",
+		"```",
+		"\x1b[32mafter\x1b[0m",
+	}, "\n")
+	want := strings.Join([]string{
+		"not art",
+		"```ansi",
+		"\x1b[1;31mRED\x1b[0m \x1b[38;5;82mGREEN\x1b[0m",
+		"```",
+		"```",
+		"code",
+		"```",
+		"after",
+	}, "\n")
+	if got := SanitizeMarkdown(in); got != want {
+		t.Errorf("SanitizeMarkdown:\n got %q\nwant %q", got, want)
+	}
+}
+
+func TestLoadStoreSanitizes(t *testing.T) {
+	dir := t.TempDir()
+	article := `---
+title: "Evil\e]0;pwned\a Title"
+author: "Mal\u009b31mlory"
+volume: 1
+order: 1
+category: "guide\e[2J"
+tags: [ok, "bad\e]52;c;aGk=\atag"]
+draft: false
+challenges:
+  - id: "c1"
+    title: "Chal\e[5mlenge"
+    points: 10
+    flag_sha256: "` + strings.Repeat("a", 64) + `"
+---
+
+Body with a ` + "\x1b]8;;https://evil.example\x1b\\link\x1b]8;;\x1b\\" + `.
+`
+	page := "---\ntitle: \"About\\e[8m\"\n---\n\nHidden\x1b[8m text\n"
+	poll := "question: \"Which\\e]52;c;aGk=\\a?\"\noptions: [\"one\\e[1m\", two]\n"
+
+	write := func(path, data string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(filepath.Join(dir, "issues", "vol1", "01-evil.md"), article)
+	write(filepath.Join(dir, "pages", "about.md"), page)
+	write(filepath.Join(dir, "polls", "evil.yaml"), poll)
+
+	store := LoadStore(dir, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if len(store.Articles) != 1 || len(store.Pages) != 1 || len(store.Polls) != 1 {
+		t.Fatalf("loaded %d articles, %d pages, %d polls; want 1 of each (warnings: %q)",
+			len(store.Articles), len(store.Pages), len(store.Polls), store.Warnings)
+	}
+
+	a := store.Articles[0]
+	got := []string{a.Title, a.Author, a.Category, strings.Join(a.Tags, ","), a.Challenges[0].Title, a.Body,
+		store.Pages[0].Title, store.Pages[0].Body, store.Polls[0].Question, strings.Join(store.Polls[0].Options, ",")}
+	want := []string{"Evil Title", "Mallory", "guide", "ok,badtag", "Challenge", "\nBody with a link.",
+		"About", "\nHidden text", "Which?", "one,two"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("field %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+
+	var warned int
+	for _, w := range store.Warnings {
+		if strings.HasPrefix(w, "removed terminal control sequences") {
+			warned++
+		}
+	}
+	if warned != 3 {
+		t.Errorf("got %d sanitizing warnings, want 3: %q", warned, store.Warnings)
+	}
+}
@@ -1,6 +1,9 @@
 package content
 
-import "time"
+import (
+	"log/slog"
+	"time"
+)
 
 // Article represents an issue article parsed from markdown frontmatter.
 type Article struct {
@@ -16,6 +19,16 @@ type Article struct {
 	Draft       bool
 	Slug        string // from filename: "01-smashing-the-stack"
 	Body        string // raw markdown after frontmatter
+	Challenges  []Challenge
+}
+
+// Challenge is a CTF challenge attached to an article. Only the SHA-256
+// of the flag is ever known to the server.
+type Challenge struct {
+	ID         string
+	Title      string
+	Points     int
+	FlagSHA256 string // lowercase hex
 }
 
 // Page represents a static page (about, manifesto).
@@ -32,9 +45,38 @@ type Volume struct {
 	Articles []Article // sorted by Order
 }
 
+// Poll is a reader poll defined as YAML in the content dir.
+type Poll struct {
+	ID          string // from filename: "next-volume"
+	Question    string
+	Description string
+	Options     []string
+	Closes      time.Time // last day of voting; zero means open-ended
+}
+
+// Closed reports whether voting has ended at now.
+func (p Poll) Closed(now time.Time) bool {
+	return !p.Closes.IsZero() && !now.Before(p.Closes.AddDate(0, 0, 1))
+}
+
 // Store holds all loaded content, shared read-only across SSH sessions.
 type Store struct {
 	Volumes  []Volume  // sorted by Number
 	Pages    []Page
 	Articles []Article // flat list of all non-draft articles
+	Polls    []Poll    // sorted by ID
+
+	LoadedAt time.Time
+	Warnings []string // problems found while loading, in the order seen
+
+	log *slog.Logger // where LoadStore reports problems
+}
+
+// Challenges returns every challenge in the store, in article order.
+func (s *Store) Challenges() []Challenge {
+	var out []Challenge
+	for _, a := range s.Articles {
+		out = append(out, a.Challenges...)
+	}
+	return out
 }
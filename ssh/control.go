@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"strings"
+	"time"
+	"unicode"
+
+	"terminull-ssh/access"
+	"terminull-ssh/content"
+	"terminull-ssh/nodes"
+	"terminull-ssh/ui/types"
+)
+
+// maxBroadcastLen caps a broadcast so it fits a single banner line.
+const maxBroadcastLen = 200
+
+// listenControl opens the local control socket at path. Only the user
+// running the server may connect. A stale socket left by a previous run
+// is replaced.
+func listenControl(path string) (net.Listener, error) {
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0o600); err != nil {
+		l.Close()
+		return nil, err
+	}
+	return l, nil
+}
+
+// serveControl answers sysop commands on l, one per line. Each reply is
+// zero or more lines of output ending with a line that starts with "ok"
+// or "error:".
+//
+//	broadcast <message>    show <message> to every live session
+//	ban <ip> [duration]    ban an address (default: the automatic ban length)
+//	unban <ip>             lift a ban
+//	bans                   list bans in force
+func serveControl(l net.Listener, reg *nodes.Registry, guard *access.Guard, logger *slog.Logger) {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				logger.Error("accept failed", "err", err)
+			}
+			return
+		}
+		go handleControl(conn, reg, guard, logger)
+	}
+}
+
+func handleControl(conn net.Conn, reg *nodes.Registry, guard *access.Guard, logger *slog.Logger) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+
+	sc := bufio.NewScanner(conn)
+	for sc.Scan() {
+		cmd, arg, _ := strings.Cut(strings.TrimSpace(sc.Text()), " ")
+		switch cmd {
+		case "":
+			continue
+		case "broadcast":
+			text := cleanBroadcast(arg)
+			if text == "" {
+				fmt.Fprintln(conn, "error: empty message")
+				continue
+			}
+			n := reg.Broadcast(types.BroadcastMsg{Text: text})
+			logger.Info("broadcast", "sessions", n, "text", text)
+			fmt.Fprintf(conn, "ok: sent to %d sessions\n", n)
+		case "ban":
+			ip, dur, _ := strings.Cut(strings.TrimSpace(arg), " ")
+			var d time.Duration
+			if dur != "" {
+				var err error
+				if d, err = time.ParseDuration(strings.TrimSpace(dur)); err != nil {
+					fmt.Fprintf(conn, "error: bad duration %q\n", dur)
+					continue
+				}
+			}
+			if err := guard.Ban(ip, d, "sysop"); err != nil {
+				fmt.Fprintf(conn, "error: %v\n", err)
+				continue
+			}
+			logger.Info("banned", "ip", ip, "for", d)
+			fmt.Fprintf(conn, "ok: banned %s\n", ip)
+		case "unban":
+			ok, err := guard.Unban(arg)
+			switch {
+			case err != nil:
+				fmt.Fprintf(conn, "error: %v\n", err)
+			case !ok:
+				fmt.Fprintf(conn, "error: %s is not banned\n", arg)
+			default:
+				logger.Info("unbanned", "ip", arg)
+				fmt.Fprintf(conn, "ok: unbanned %s\n", arg)
+			}
+		case "bans":
+			bans := guard.Bans()
+			for _, b := range bans {
+				fmt.Fprintf(conn, "%-40s until %s  (%s)\n", b.IP, b.Until.UTC().Format(time.RFC3339), b.Reason)
+			}
+			fmt.Fprintf(conn, "ok: %d bans\n", len(bans))
+		default:
+			fmt.Fprintf(conn, "error: unknown command %q\n", cmd)
+		}
+	}
+}
+
+// cleanBroadcast strips escape sequences and control characters from a
+// broadcast and caps its length.
+func cleanBroadcast(s string) string {
+	s = content.SanitizeLine(s)
+	var b strings.Builder
+	n := 0
+	for _, r := range s {
+		if n == maxBroadcastLen {
+			break
+		}
+		if unicode.IsSpace(r) {
+			r = ' '
+		} else if !unicode.IsPrint(r) {
+			continue
+		}
+		b.WriteRune(r)
+		n++
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// controlCommands are the commands `terminull-ssh <command> [args]` sends
+// to a running server.
+var controlCommands = map[string]bool{"broadcast": true, "ban": true, "unban": true, "bans": true}
+
+// sendControl is the client side of the control socket. It sends one
+// command line and copies the reply to stdout.
+func sendControl(path, line string) error {
+	if path == "" {
+		return errors.New("no control socket configured (set --control-socket)")
+	}
+	conn, err := net.DialTimeout("unix", path, 5*time.Second)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := fmt.Fprintf(conn, "%s\n", line); err != nil {
+		return err
+	}
+	r := bufio.NewReader(conn)
+	for {
+		reply, err := r.ReadString('\n')
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return errors.New("connection closed before a reply")
+			}
+			return err
+		}
+		reply = strings.TrimRight(reply, "\n")
+		if msg, ok := strings.CutPrefix(reply, "error: "); ok {
+			return errors.New(msg)
+		}
+		fmt.Println(reply)
+		if strings.HasPrefix(reply, "ok") {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package ctf
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"terminull-ssh/content"
+)
+
+// ErrNoKey is returned when a guest without an SSH key submits a flag.
+var ErrNoKey = errors.New("connect with an SSH key to submit flags")
+
+// player is the persisted record of one key identity.
+type player struct {
+	Handle string               `json:"handle"` // handle used on the latest solve
+	Solves map[string]time.Time `json:"solves"` // challenge ID → solve time
+}
+
+// Standing is one row of the scoreboard.
+type Standing struct {
+	Handle    string
+	Points    int
+	Solves    int
+	LastSolve time.Time
+}
+
+// Scoreboard records solves per key identity and is shared by all
+// sessions. Flags are only ever handled as SHA-256 digests; the plaintext
+// never leaves Submit.
+type Scoreboard struct {
+	path string
+
+	mu      sync.Mutex
+	players map[string]*player // hashed key ID → player
+}
+
+// Open loads the solves stored at path, starting empty if it does not exist.
+func Open(path string) (*Scoreboard, error) {
+	sb := &Scoreboard{path: path, players: make(map[string]*player)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return sb, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &sb.players); err != nil {
+		return nil, err
+	}
+	return sb, nil
+}
+
+// playerID derives the stored identity for a key fingerprint.
+func playerID(keyID string) string {
+	sum := sha256.Sum256([]byte("ctf\x00" + keyID))
+	return hex.EncodeToString(sum[:])
+}
+
+// Submit checks flag against challenges. It returns the challenge the
+// flag solves (nil if none) and whether keyID had already solved it.
+func (sb *Scoreboard) Submit(challenges []content.Challenge, keyID, handle, flag string) (*content.Challenge, bool, error) {
+	if keyID == "" {
+		return nil, false, ErrNoKey
+	}
+
+	sum := sha256.Sum256([]byte(strings.TrimSpace(flag)))
+	var solved *content.Challenge
+	for i := range challenges {
+		want, err := hex.DecodeString(challenges[i].FlagSHA256)
+		if err != nil {
+			continue
+		}
+		if subtle.ConstantTimeCompare(sum[:], want) == 1 {
+			solved = &challenges[i]
+			break
+		}
+	}
+	if solved == nil {
+		return nil, false, nil
+	}
+
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+
+	id := playerID(keyID)
+	p := sb.players[id]
+	if p == nil {
+		p = &player{Solves: make(map[string]time.Time)}
+		sb.players[id] = p
+	}
+	if _, ok := p.Solves[solved.ID]; ok {
+		return solved, true, nil
+	}
+	p.Handle = handle
+	p.Solves[solved.ID] = time.Now()
+	if err := sb.save(); err != nil {
+		delete(p.Solves, solved.ID)
+		return nil, false, err
+	}
+	return solved, false, nil
+}
+
+// Solved reports whether keyID has solved the challenge with the given ID.
+func (sb *Scoreboard) Solved(keyID, challengeID string) bool {
+	if keyID == "" {
+		return false
+	}
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	p := sb.players[playerID(keyID)]
+	if p == nil {
+		return false
+	}
+	_, ok := p.Solves[challengeID]
+	return ok
+}
+
+// Standings ranks players by points, breaking ties by who got there first.
+// Points come from the current content, so solves of removed challenges
+// no longer count.
+func (sb *Scoreboard) Standings(challenges []content.Challenge) []Standing {
+	points := make(map[string]int, len(challenges))
+	for _, c := range challenges {
+		points[c.ID] = c.Points
+	}
+
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+
+	var out []Standing
+	for _, p := range sb.players {
+		s := Standing{Handle: p.Handle}
+		for id, at := range p.Solves {
+			pts, ok := points[id]
+			if !ok {
+				continue
+			}
+			s.Points += pts
+			s.Solves++
+			if at.After(s.LastSolve) {
+				s.LastSolve = at
+			}
+		}
+		if s.Solves > 0 {
+			out = append(out, s)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Points != out[j].Points {
+			return out[i].Points > out[j].Points
+		}
+		return out[i].LastSolve.Before(out[j].LastSolve)
+	})
+	return out
+}
+
+// save writes the solves atomically. Caller must hold sb.mu.
+func (sb *Scoreboard) save() error {
+	data, err := json.MarshalIndent(sb.players, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(sb.path), 0o755); err != nil {
+		return err
+	}
+	tmp := sb.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, sb.path)
+}
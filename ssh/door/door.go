@@ -0,0 +1,256 @@
+package door
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sort"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// defaultTimeLimit applies to doors that do not set time_limit.
+const defaultTimeLimit = 30 * time.Minute
+
+var (
+	// ErrBusy is returned when a door or the whole door system is at its
+	// concurrency cap.
+	ErrBusy = errors.New("all door lines are busy")
+
+	// ErrTimeLimit is returned when a caller runs out of door time.
+	ErrTimeLimit = errors.New("door time limit reached")
+)
+
+// Door is an in-process door implementation. Run owns the terminal until
+// it returns; it must return promptly once ctx is done.
+type Door interface {
+	Run(ctx context.Context, info DropInfo, stdin io.Reader, stdout io.Writer) error
+}
+
+// builtins holds in-process doors that can be referenced from the doors file.
+var builtins = map[string]Door{
+	"guess": guessDoor{},
+}
+
+// Spec describes one door as configured by the sysop.
+type Spec struct {
+	Name          string        `yaml:"name"`
+	Description   string        `yaml:"description"`
+	Command       string        `yaml:"command"` // external executable
+	Args          []string      `yaml:"args"`    // {dropdir}, {doorsys}, {dorinfo}, {node} are expanded
+	Builtin       string        `yaml:"builtin"` // name of an in-process door
+	TimeLimit     time.Duration `yaml:"time_limit"`
+	MaxConcurrent int           `yaml:"max_concurrent"`
+}
+
+// doorsFile is the on-disk YAML layout of the doors file.
+type doorsFile struct {
+	MaxConcurrent int    `yaml:"max_concurrent"`
+	Doors         []Spec `yaml:"doors"`
+}
+
+// Registry holds the configured doors and enforces concurrency caps.
+// It is shared by all SSH sessions.
+type Registry struct {
+	specs         []Spec
+	maxConcurrent int
+
+	mu      sync.Mutex
+	total   int
+	running map[string]int
+}
+
+// Load reads a doors YAML file. An empty path yields an empty registry.
+func Load(path string) (*Registry, error) {
+	r := &Registry{running: make(map[string]int)}
+	if path == "" {
+		return r, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var f doorsFile
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	seen := make(map[string]bool)
+	for _, s := range f.Doors {
+		switch {
+		case s.Name == "":
+			return nil, fmt.Errorf("%s: door without a name", path)
+		case seen[s.Name]:
+			return nil, fmt.Errorf("%s: duplicate door %q", path, s.Name)
+		case (s.Command == "") == (s.Builtin == ""):
+			return nil, fmt.Errorf("%s: door %q needs exactly one of command or builtin", path, s.Name)
+		case s.Builtin != "" && builtins[s.Builtin] == nil:
+			return nil, fmt.Errorf("%s: door %q: unknown builtin %q", path, s.Name, s.Builtin)
+		}
+		if s.TimeLimit <= 0 {
+			s.TimeLimit = defaultTimeLimit
+		}
+		seen[s.Name] = true
+		r.specs = append(r.specs, s)
+	}
+	r.maxConcurrent = f.MaxConcurrent
+
+	sort.SliceStable(r.specs, func(i, j int) bool { return r.specs[i].Name < r.specs[j].Name })
+	return r, nil
+}
+
+// Doors returns the configured doors sorted by name.
+func (r *Registry) Doors() []Spec {
+	if r == nil {
+		return nil
+	}
+	return r.specs
+}
+
+// Len returns the number of configured doors.
+func (r *Registry) Len() int {
+	if r == nil {
+		return 0
+	}
+	return len(r.specs)
+}
+
+// Running returns how many callers are currently inside the named door.
+func (r *Registry) Running(name string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.running[name]
+}
+
+// acquire reserves a slot for spec, returning a release func.
+func (r *Registry) acquire(spec Spec) (func(), error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.maxConcurrent > 0 && r.total >= r.maxConcurrent {
+		return nil, ErrBusy
+	}
+	if spec.MaxConcurrent > 0 && r.running[spec.Name] >= spec.MaxConcurrent {
+		return nil, ErrBusy
+	}
+	r.total++
+	r.running[spec.Name]++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			r.mu.Lock()
+			r.total--
+			r.running[spec.Name]--
+			r.mu.Unlock()
+		})
+	}, nil
+}
+
+// Command is a door launch prepared for tea.Exec.
+type Command struct {
+	spec    Spec
+	info    DropInfo
+	release func()
+	stdin   io.Reader
+	stdout  io.Writer
+}
+
+// Prepare reserves a slot in the named door for the caller described by
+// info. The returned Command must be run (or closed) to free the slot.
+func (r *Registry) Prepare(name string, info DropInfo) (*Command, error) {
+	for _, s := range r.Doors() {
+		if s.Name != name {
+			continue
+		}
+		release, err := r.acquire(s)
+		if err != nil {
+			return nil, err
+		}
+		info.Minutes = int(s.TimeLimit / time.Minute)
+		return &Command{spec: s, info: info, release: release}, nil
+	}
+	return nil, fmt.Errorf("no such door %q", name)
+}
+
+// SetStdin implements tea.ExecCommand.
+func (c *Command) SetStdin(r io.Reader) { c.stdin = r }
+
+// SetStdout implements tea.ExecCommand.
+func (c *Command) SetStdout(w io.Writer) { c.stdout = w }
+
+// SetStderr implements tea.ExecCommand. Door stderr is sent to the
+// caller's terminal rather than the server log, so it is ignored here.
+func (c *Command) SetStderr(io.Writer) {}
+
+// Close frees the door slot. It is safe to call more than once.
+func (c *Command) Close() { c.release() }
+
+// Run writes the drop files and runs the door until it exits or the time
+// limit is reached. Implements tea.ExecCommand.
+func (c *Command) Run() error {
+	defer c.Close()
+
+	dir, err := os.MkdirTemp("", "terminull-door-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	if err := writeDropFiles(dir, c.info); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.spec.TimeLimit)
+	defer cancel()
+
+	if c.spec.Builtin != "" {
+		err = c.runBuiltin(ctx)
+	} else {
+		err = c.runExternal(ctx, dir)
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		return ErrTimeLimit
+	}
+	return err
+}
+
+func (c *Command) runExternal(ctx context.Context, dir string) error {
+	args := make([]string, len(c.spec.Args))
+	for i, a := range c.spec.Args {
+		args[i] = expandArg(a, dir, c.info)
+	}
+
+	cmd := exec.CommandContext(ctx, c.spec.Command, args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), c.info.environ(dir)...)
+	cmd.Stdin = c.stdin
+	cmd.Stdout = c.stdout
+	// A recorded session writes through a tee; the program still needs the
+	// terminal itself to see a TTY.
+	if t, ok := c.stdout.(interface{ Terminal() *os.File }); ok {
+		cmd.Stdout = t.Terminal()
+	}
+	cmd.Stderr = cmd.Stdout
+	return cmd.Run()
+}
+
+func (c *Command) runBuiltin(ctx context.Context) error {
+	// Unblock a pending read on the caller's terminal when time runs out,
+	// so the door goroutine cannot swallow input meant for the BBS.
+	if d, ok := c.stdin.(interface{ SetReadDeadline(time.Time) error }); ok {
+		stop := context.AfterFunc(ctx, func() { _ = d.SetReadDeadline(time.Now()) })
+		defer func() {
+			stop()
+			_ = d.SetReadDeadline(time.Time{})
+		}()
+	}
+	return builtins[c.spec.Builtin].Run(ctx, c.info, c.stdin, c.stdout)
+}
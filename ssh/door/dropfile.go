@@ -0,0 +1,136 @@
+package door
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Drop file names written into the door's temp directory.
+const (
+	doorSysName = "DOOR.SYS"
+	dorinfoName = "DORINFO1.DEF"
+)
+
+// DropInfo describes the caller handed to a door.
+type DropInfo struct {
+	Handle  string
+	Node    int
+	Width   int
+	Height  int
+	Minutes int // time allowed in the door, filled in by Prepare
+}
+
+// environ returns the env vars exported to external doors.
+func (d DropInfo) environ(dir string) []string {
+	return []string{
+		"TERM=xterm-256color",
+		"COLUMNS=" + strconv.Itoa(d.Width),
+		"LINES=" + strconv.Itoa(d.Height),
+		"DOOR_DROPDIR=" + dir,
+		"DOOR_NODE=" + strconv.Itoa(d.Node),
+		"DOOR_HANDLE=" + d.Handle,
+	}
+}
+
+// expandArg substitutes drop file placeholders in a door argument.
+func expandArg(arg, dir string, d DropInfo) string {
+	return strings.NewReplacer(
+		"{dropdir}", dir,
+		"{doorsys}", filepath.Join(dir, doorSysName),
+		"{dorinfo}", filepath.Join(dir, dorinfoName),
+		"{node}", strconv.Itoa(d.Node),
+	).Replace(arg)
+}
+
+// writeDropFiles writes DOOR.SYS and DORINFO1.DEF into dir.
+func writeDropFiles(dir string, d DropInfo) error {
+	if err := os.WriteFile(filepath.Join(dir, doorSysName), []byte(doorSys(d, time.Now())), 0o600); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, dorinfoName), []byte(dorinfo(d)), 0o600)
+}
+
+// doorSys renders the 52-line GAP-style DOOR.SYS drop file.
+func doorSys(d DropInfo, now time.Time) string {
+	date := now.Format("01/02/06")
+	clock := now.Format("15:04")
+	lines := []string{
+		"COM0:",                                 // 1  comm port (0 = local)
+		"115200",                                // 2  baud rate
+		"8",                                     // 3  data bits
+		strconv.Itoa(d.Node),                    // 4  node number
+		"115200",                                // 5  locked DTE rate
+		"Y",                                     // 6  screen display
+		"N",                                     // 7  printer toggle
+		"N",                                     // 8  page bell
+		"N",                                     // 9  caller alarm
+		d.Handle,                                // 10 user full name
+		"terminull",                             // 11 calling from
+		"000-000-0000",                          // 12 home phone
+		"000-000-0000",                          // 13 work phone
+		"",                                      // 14 password
+		"10",                                    // 15 security level
+		"1",                                     // 16 total times on
+		date,                                    // 17 last date called
+		strconv.Itoa(d.Minutes * 60),            // 18 seconds remaining
+		strconv.Itoa(d.Minutes),                 // 19 minutes remaining
+		"GR",                                    // 20 graphics mode
+		strconv.Itoa(d.Height),                  // 21 screen length
+		"Y",                                     // 22 expert mode
+		"",                                      // 23 conferences registered
+		"0",                                     // 24 conference exited to door
+		"12/31/99",                              // 25 expiration date
+		strconv.Itoa(d.Node),                    // 26 user record number
+		"Z",                                     // 27 default protocol
+		"0",                                     // 28 total uploads
+		"0",                                     // 29 total downloads
+		"0",                                     // 30 daily download K
+		"0",                                     // 31 max daily download K
+		"01/01/70",                              // 32 birthdate
+		"",                                      // 33 path to main directory
+		"",                                      // 34 path to GEN directory
+		"SYSOP",                                 // 35 sysop name
+		d.Handle,                                // 36 alias
+		"00:00",                                 // 37 event time
+		"Y",                                     // 38 error correcting connection
+		"N",                                     // 39 ANSI in NG mode
+		"Y",                                     // 40 record locking
+		"7",                                     // 41 default color
+		"0",                                     // 42 time credits
+		date,                                    // 43 last new files scan
+		clock,                                   // 44 time of this call
+		clock,                                   // 45 time of last call
+		"9999",                                  // 46 max daily files
+		"0",                                     // 47 files downloaded today
+		"0",                                     // 48 total K uploaded
+		"0",                                     // 49 total K downloaded
+		fmt.Sprintf("%dx%d", d.Width, d.Height), // 50 comment (terminal size)
+		"0",                                     // 51 doors opened
+		"0",                                     // 52 messages left
+	}
+	return strings.Join(lines, "\r\n") + "\r\n"
+}
+
+// dorinfo renders the 13-line DORINFO1.DEF drop file.
+func dorinfo(d DropInfo) string {
+	lines := []string{
+		"terminull",             // BBS name
+		"SYSOP",                 // sysop first name
+		"",                      // sysop last name
+		"COM0",                  // comm port
+		"115200 BAUD,N,8,1",     // baud
+		"0",                     // networked
+		d.Handle,                // user first name
+		"",                      // user last name
+		"terminull",             // location
+		"1",                     // graphics (1 = ANSI)
+		"10",                    // security level
+		strconv.Itoa(d.Minutes), // minutes remaining
+		"-1",                    // FOSSIL
+	}
+	return strings.Join(lines, "\r\n") + "\r\n"
+}
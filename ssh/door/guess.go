@@ -0,0 +1,47 @@
+package door
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+)
+
+// guessDoor is a tiny built-in number guessing game, useful as a smoke
+// test for the door system and as an example in-process door.
+type guessDoor struct{}
+
+func (guessDoor) Run(ctx context.Context, info DropInfo, stdin io.Reader, stdout io.Writer) error {
+	secret := rand.IntN(100) + 1
+	in := bufio.NewScanner(stdin)
+
+	fmt.Fprintf(stdout, "\r\n  GUESS THE NUMBER // node %d\r\n\r\n", info.Node)
+	fmt.Fprintf(stdout, "  Welcome, %s. I'm thinking of a number from 1 to 100.\r\n", info.Handle)
+	fmt.Fprint(stdout, "  Empty line to leave.\r\n\r\n")
+
+	for tries := 1; ; tries++ {
+		fmt.Fprint(stdout, "  guess> ")
+		if !in.Scan() {
+			return ctx.Err()
+		}
+		line := strings.TrimSpace(in.Text())
+		if line == "" {
+			return nil
+		}
+		n, err := strconv.Atoi(line)
+		switch {
+		case err != nil:
+			fmt.Fprint(stdout, "  Numbers only.\r\n")
+		case n < secret:
+			fmt.Fprint(stdout, "  Higher.\r\n")
+		case n > secret:
+			fmt.Fprint(stdout, "  Lower.\r\n")
+		default:
+			fmt.Fprintf(stdout, "  Got it in %d. Returning to the BBS...\r\n", tries)
+			return nil
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"bytes"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/keygen"
+	"github.com/charmbracelet/ssh"
+	"github.com/charmbracelet/wish"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// OpenSSH's host key update extension (PROTOCOL, section 2.5). After login
+// the server lists every key it holds; clients that do not know some of
+// them ask for proof of ownership and then add them to known_hosts. This
+// lets a new key be rolled out before it is ever used in a handshake.
+const (
+	hostKeysAnnounce = "hostkeys-00@openssh.com"
+	hostKeysProve    = "hostkeys-prove-00@openssh.com"
+)
+
+// hostKeys is the server's set of host keys.
+type hostKeys struct {
+	active []gossh.Signer // offered in the handshake, one per key type
+	all    []gossh.Signer // active plus keys being rotated in
+}
+
+// loadHostKeys reads the host keys at active and next, generating any
+// that are missing. A key's type comes from its file name: names
+// containing "rsa" or "ecdsa" get those types, anything else ed25519.
+func loadHostKeys(active, next []string, logger *slog.Logger) (*hostKeys, error) {
+	var hk hostKeys
+	seen := make(map[string]string) // key type → path of the active key
+	for i, path := range append(append([]string{}, active...), next...) {
+		signer, err := loadHostKey(path, logger)
+		if err != nil {
+			return nil, fmt.Errorf("host key %s: %w", path, err)
+		}
+		pub := signer.PublicKey()
+		rotating := i >= len(active)
+		if !rotating {
+			if other, ok := seen[pub.Type()]; ok {
+				return nil, fmt.Errorf("host keys %s and %s are both %s; only one key of each type can be offered, so list the new one in next_host_keys", other, path, pub.Type())
+			}
+			seen[pub.Type()] = path
+			hk.active = append(hk.active, signer)
+		}
+		hk.all = append(hk.all, signer)
+		logger.Info("host key", "type", pub.Type(), "fingerprint", gossh.FingerprintSHA256(pub), "path", path, "next", rotating)
+	}
+	if len(hk.active) == 0 {
+		return nil, errors.New("no host keys configured")
+	}
+	return &hk, nil
+}
+
+// loadHostKey reads the private key at path, generating it with 0600
+// permissions if it does not exist yet.
+func loadHostKey(path string, logger *slog.Logger) (gossh.Signer, error) {
+	info, err := os.Stat(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			return nil, err
+		}
+		kp, err := keygen.New(path, keygen.WithKeyType(hostKeyType(path)), keygen.WithEllipticCurve(elliptic.P256()), keygen.WithWrite())
+		if err != nil {
+			return nil, err
+		}
+		logger.Info("generated host key", "path", path)
+		return kp.Signer(), nil
+	case err != nil:
+		return nil, err
+	case info.Mode().Perm()&0o077 != 0:
+		logger.Warn("host key is readable by other users; chmod 600 it", "path", path, "mode", info.Mode().Perm().String())
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return gossh.ParsePrivateKey(data)
+}
+
+// hostKeyType picks the type of key to generate for path.
+func hostKeyType(path string) keygen.KeyType {
+	name := strings.ToLower(filepath.Base(path))
+	switch {
+	case strings.Contains(name, "ecdsa"):
+		return keygen.ECDSA
+	case strings.Contains(name, "rsa"):
+		return keygen.RSA
+	}
+	return keygen.Ed25519
+}
+
+// option installs the active keys and answers clients' proof requests.
+func (hk *hostKeys) option() ssh.Option {
+	return func(s *ssh.Server) error {
+		for _, k := range hk.active {
+			s.AddHostKey(k)
+		}
+		if s.RequestHandlers == nil {
+			s.RequestHandlers = make(map[string]ssh.RequestHandler)
+			for k, v := range ssh.DefaultRequestHandlers {
+				s.RequestHandlers[k] = v
+			}
+		}
+		s.RequestHandlers[hostKeysProve] = hk.prove
+		return nil
+	}
+}
+
+// announcedKey is the connection context key set once the host keys have
+// been announced on it.
+type announcedKey struct{}
+
+// middleware tells each client about all of the server's host keys, once
+// per connection as sshd does: OpenSSH drops a connection announcing them
+// twice, which would kill every session multiplexed onto it but the first.
+func (hk *hostKeys) middleware() wish.Middleware {
+	var payload []byte
+	for _, k := range hk.all {
+		payload = append(payload, gossh.Marshal(struct{ Key []byte }{k.PublicKey().Marshal()})...)
+	}
+	return func(next ssh.Handler) ssh.Handler {
+		return func(sess ssh.Session) {
+			ctx := sess.Context()
+			ctx.Lock()
+			announced := ctx.Value(announcedKey{}) != nil
+			ctx.SetValue(announcedKey{}, true)
+			ctx.Unlock()
+			if conn, ok := ctx.Value(ssh.ContextKeyConn).(gossh.Conn); ok && !announced {
+				_, _, _ = conn.SendRequest(hostKeysAnnounce, false, payload)
+			}
+			next(sess)
+		}
+	}
+}
+
+// prove signs, for each key the client asks about, the session ID and
+// the key itself, showing that the server holds its private half.
+func (hk *hostKeys) prove(ctx ssh.Context, _ *ssh.Server, req *gossh.Request) (bool, []byte) {
+	sessionID, err := hex.DecodeString(ctx.SessionID())
+	if err != nil {
+		return false, nil
+	}
+
+	var resp []byte
+	rest := req.Payload
+	for len(rest) > 0 {
+		var blob struct {
+			Key  []byte
+			Rest []byte `ssh:"rest"`
+		}
+		if err := gossh.Unmarshal(rest, &blob); err != nil {
+			return false, nil
+		}
+		rest = blob.Rest
+
+		signer := hk.find(blob.Key)
+		if signer == nil {
+			return false, nil
+		}
+		data := gossh.Marshal(struct {
+			Purpose   string
+			SessionID []byte
+			Key       []byte
+		}{hostKeysProve, sessionID, blob.Key})
+
+		var sig *gossh.Signature
+		if as, ok := signer.(gossh.AlgorithmSigner); ok && signer.PublicKey().Type() == gossh.KeyAlgoRSA {
+			sig, err = as.SignWithAlgorithm(rand.Reader, data, gossh.KeyAlgoRSASHA512)
+		} else {
+			sig, err = signer.Sign(rand.Reader, data)
+		}
+		if err != nil {
+			return false, nil
+		}
+		resp = append(resp, gossh.Marshal(struct{ Sig []byte }{gossh.Marshal(sig)})...)
+	}
+	return true, resp
+}
+
+// find returns the signer for the public key blob, if the server has it.
+func (hk *hostKeys) find(blob []byte) gossh.Signer {
+	for _, k := range hk.all {
+		if bytes.Equal(k.PublicKey().Marshal(), blob) {
+			return k
+		}
+	}
+	return nil
+}
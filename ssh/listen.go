@@ -0,0 +1,128 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/coreos/go-systemd/v22/activation"
+	"github.com/coreos/go-systemd/v22/daemon"
+	"github.com/pires/go-proxyproto"
+
+	"terminull-ssh/access"
+)
+
+// unixPrefix marks a listen address as a unix socket path.
+const unixPrefix = "unix:"
+
+// listenAddrs returns the addresses to listen on: cfg.Listen if set,
+// otherwise host:port.
+func listenAddrs(cfg Config) []string {
+	if addrs := splitList(cfg.Listen); len(addrs) > 0 {
+		return addrs
+	}
+	return []string{net.JoinHostPort(cfg.Host, fmt.Sprint(cfg.Port))}
+}
+
+// openListeners returns the sockets systemd handed over when the server
+// is socket-activated, or else opens every configured address.
+func openListeners(cfg Config, logger *slog.Logger) ([]net.Listener, error) {
+	lns, err := activation.Listeners()
+	if err != nil {
+		return nil, fmt.Errorf("systemd sockets: %w", err)
+	}
+	if len(lns) > 0 {
+		logger.Info("using sockets from systemd", "count", len(lns))
+		return lns, nil
+	}
+
+	for _, addr := range listenAddrs(cfg) {
+		ln, err := listen(addr)
+		if err != nil {
+			for _, l := range lns {
+				l.Close()
+			}
+			return nil, fmt.Errorf("listen on %s: %w", addr, err)
+		}
+		lns = append(lns, ln)
+	}
+	return lns, nil
+}
+
+// listen opens one TCP address or, with the unix: prefix, a unix socket,
+// replacing a socket left behind by an earlier run.
+func listen(addr string) (net.Listener, error) {
+	path, ok := strings.CutPrefix(addr, unixPrefix)
+	if !ok {
+		return net.Listen("tcp", addr)
+	}
+	if info, err := os.Lstat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+	return net.Listen("unix", path)
+}
+
+// checkListen validates a listen address.
+func checkListen(addr string) error {
+	if path, ok := strings.CutPrefix(addr, unixPrefix); ok {
+		if path == "" {
+			return errors.New("unix socket path is empty")
+		}
+		return nil
+	}
+	_, _, err := net.SplitHostPort(addr)
+	return err
+}
+
+// parseProxyFrom parses the proxies whose PROXY protocol headers are
+// trusted: networks, plus "unix" for connections over unix sockets.
+func parseProxyFrom(s string) (nets []*net.IPNet, unix bool, err error) {
+	var cidrs []string
+	for _, f := range splitList(s) {
+		if f == "unix" {
+			unix = true
+			continue
+		}
+		cidrs = append(cidrs, f)
+	}
+	nets, err = access.ParseCIDRs(strings.Join(cidrs, ","))
+	return nets, unix, err
+}
+
+// proxyListener reads PROXY protocol (v1 or v2) headers on connections
+// from trusted proxies, so the rest of the server sees the real caller's
+// address. Trusted proxies must send a header; anyone else is taken as
+// calling directly and any header they send is refused.
+func proxyListener(ln net.Listener, trusted []*net.IPNet, unix bool) net.Listener {
+	return &proxyproto.Listener{
+		Listener: ln,
+		Policy: func(upstream net.Addr) (proxyproto.Policy, error) {
+			ip := access.IP(upstream)
+			if ip == nil {
+				if unix {
+					return proxyproto.REQUIRE, nil
+				}
+				return proxyproto.REJECT, nil
+			}
+			for _, n := range trusted {
+				if n.Contains(ip) {
+					return proxyproto.REQUIRE, nil
+				}
+			}
+			return proxyproto.REJECT, nil
+		},
+	}
+}
+
+// notify tells systemd about a state change. It does nothing when the
+// server was not started by systemd.
+func notify(state string, logger *slog.Logger) {
+	if _, err := daemon.SdNotify(false, state); err != nil {
+		logger.Warn("could not notify systemd", "state", state, "err", err)
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/ssh"
+	"github.com/charmbracelet/wish"
+)
+
+// IP logging modes for Config.LogIP.
+const (
+	logIPFull     = "full"     // log remote addresses as they are
+	logIPTruncate = "truncate" // keep only the network: /24 for IPv4, /48 for IPv6
+	logIPNone     = "none"     // never log remote addresses
+)
+
+// newLogger builds the server's structured logger from cfg.
+func newLogger(cfg Config, w io.Writer) (*slog.Logger, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(cfg.LogLevel)); err != nil {
+		return nil, fmt.Errorf("log level %q: want debug, info, warn or error", cfg.LogLevel)
+	}
+	opts := &slog.HandlerOptions{Level: level}
+
+	switch strings.ToLower(cfg.LogFormat) {
+	case "text":
+		return slog.New(slog.NewTextHandler(w, opts)), nil
+	case "json":
+		return slog.New(slog.NewJSONHandler(w, opts)), nil
+	}
+	return nil, fmt.Errorf("log format %q: want text or json", cfg.LogFormat)
+}
+
+// checkLogIP validates an IP logging mode.
+func checkLogIP(mode string) error {
+	switch mode {
+	case logIPFull, logIPTruncate, logIPNone:
+		return nil
+	}
+	return fmt.Errorf("log IP mode %q: want full, truncate or none", mode)
+}
+
+// fatal logs msg at error level and exits.
+func fatal(logger *slog.Logger, msg string, args ...any) {
+	logger.Error(msg, args...)
+	os.Exit(1)
+}
+
+// remoteAddr formats addr for the log according to mode. Ports are
+// dropped unless everything is being logged.
+func remoteAddr(addr net.Addr, mode string) string {
+	if mode == logIPFull {
+		return addr.String()
+	}
+	if mode != logIPTruncate {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		host = addr.String()
+	}
+	ip := net.ParseIP(host)
+	switch {
+	case ip == nil:
+		return ""
+	case ip.To4() != nil:
+		return ip.Mask(net.CIDRMask(24, 32)).String() + "/24"
+	default:
+		return ip.Mask(net.CIDRMask(48, 128)).String() + "/48"
+	}
+}
+
+// logKey is the session context key holding the session's *slog.Logger.
+type logKey struct{}
+
+// sessionLogger returns the logger tagged with sess's ID, falling back to
+// base before logMiddleware has run.
+func sessionLogger(sess ssh.Session, base *slog.Logger) *slog.Logger {
+	if l, ok := sess.Context().Value(logKey{}).(*slog.Logger); ok {
+		return l
+	}
+	return base
+}
+
+// logMiddleware gives each session a logger carrying its ID, so every line
+// about the session can be tied together, and logs connects and
+// disconnects.
+func logMiddleware(base *slog.Logger, ipMode string) wish.Middleware {
+	return func(next ssh.Handler) ssh.Handler {
+		return func(sess ssh.Session) {
+			id := sess.Context().SessionID()
+			if len(id) > 12 {
+				id = id[:12]
+			}
+			logger := base.With("session", id)
+			sess.Context().SetValue(logKey{}, logger)
+
+			pty, _, _ := sess.Pty()
+			attrs := []any{
+				"user", sanitizeUsername(sess.User()),
+				"member", sess.PublicKey() != nil,
+				"command", strings.Join(sess.Command(), " "),
+				"term", pty.Term,
+				"width", pty.Window.Width,
+				"height", pty.Window.Height,
+				"client", sess.Context().ClientVersion(), // slog quotes or escapes anything odd
+			}
+			if addr := remoteAddr(sess.RemoteAddr(), ipMode); addr != "" {
+				attrs = append(attrs, "remote", addr)
+			}
+			logger.Info("connect", attrs...)
+
+			start := time.Now()
+			next(sess)
+			logger.Info("disconnect", "duration", time.Since(start).Round(time.Millisecond))
+		}
+	}
+}
@@ -2,11 +2,14 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
-	"log"
+	"log/slog"
+	"net"
 	"os"
 	"os/signal"
-	"regexp"
+	"path/filepath"
 	"strings"
 	"syscall"
 	"time"
@@ -18,22 +21,34 @@ import (
 	"github.com/charmbracelet/wish"
 	"github.com/charmbracelet/wish/activeterm"
 	"github.com/charmbracelet/wish/bubbletea"
-	"github.com/charmbracelet/wish/logging"
 	"github.com/charmbracelet/wish/ratelimiter"
+	"github.com/coreos/go-systemd/v22/daemon"
 	"github.com/muesli/termenv"
+	gossh "golang.org/x/crypto/ssh"
 	"golang.org/x/time/rate"
 
+	"terminull-ssh/access"
+	"terminull-ssh/callers"
 	"terminull-ssh/content"
+	"terminull-ssh/ctf"
+	"terminull-ssh/door"
+	"terminull-ssh/metrics"
+	"terminull-ssh/nodes"
+	"terminull-ssh/oneliners"
+	"terminull-ssh/render"
 	"terminull-ssh/ui"
+	"terminull-ssh/ui/layout"
+	"terminull-ssh/ui/screens"
+	"terminull-ssh/ui/theme"
+	"terminull-ssh/ui/types"
+	"terminull-ssh/votes"
 )
 
-// ansiEscapeRe matches ANSI escape sequences.
-var ansiEscapeRe = regexp.MustCompile(`\x1b\[[0-9;]*[a-zA-Z]`)
-
-// sanitizeUsername strips ANSI escapes, non-printable characters, and
-// truncates to a safe length. Returns "guest" if the result is empty.
+// sanitizeUsername strips terminal escape sequences, non-printable
+// characters, and truncates to a safe length. Returns "guest" if the
+// result is empty.
 func sanitizeUsername(s string) string {
-	s = ansiEscapeRe.ReplaceAllString(s, "")
+	s = content.SanitizeLine(s)
 	var b strings.Builder
 	for _, r := range s {
 		if r == ' ' || (unicode.IsPrint(r) && r != 0x7F) {
@@ -54,10 +69,13 @@ const maxUsernameLen = 64
 
 // usernameGuard rejects sessions with excessively long usernames
 // before they reach the bubbletea handler.
-func usernameGuard() wish.Middleware {
+func usernameGuard(guard *access.Guard, logger *slog.Logger) wish.Middleware {
 	return func(next ssh.Handler) ssh.Handler {
 		return func(sess ssh.Session) {
 			if len(sess.User()) > maxUsernameLen {
+				metrics.Rejections.WithLabelValues("username").Inc()
+				sessionLogger(sess, logger).Warn("rejected: username too long", "bytes", len(sess.User()))
+				strike(guard, sess, "username too long", sessionLogger(sess, logger))
 				fmt.Fprintf(sess, "username too long (max %d bytes)\r\n", maxUsernameLen)
 				return
 			}
@@ -66,15 +84,193 @@ func usernameGuard() wish.Middleware {
 	}
 }
 
-// clamp returns v clamped to [lo, hi].
-func clamp(v, lo, hi int) int {
-	if v < lo {
-		return lo
+// sessionMetrics counts every session and tracks how many are open.
+func sessionMetrics() wish.Middleware {
+	return func(next ssh.Handler) ssh.Handler {
+		return func(sess ssh.Session) {
+			metrics.Connections.Inc()
+			metrics.ActiveSessions.Inc()
+			defer metrics.ActiveSessions.Dec()
+			next(sess)
+		}
+	}
+}
+
+// countingLimiter counts and logs the sessions its limiter turns away,
+// giving their address a strike towards a ban.
+type countingLimiter struct {
+	ratelimiter.RateLimiter
+	guard  *access.Guard
+	logger *slog.Logger
+	ipMode string
+}
+
+func (l countingLimiter) Allow(sess ssh.Session) error {
+	err := l.RateLimiter.Allow(sess)
+	if err != nil {
+		metrics.Rejections.WithLabelValues("rate_limit").Inc()
+		attrs := []any{"user", sanitizeUsername(sess.User())}
+		if addr := remoteAddr(sess.RemoteAddr(), l.ipMode); addr != "" {
+			attrs = append(attrs, "remote", addr)
+		}
+		logger := sessionLogger(sess, l.logger)
+		logger.Warn("rejected: rate limited", attrs...)
+		strike(l.guard, sess, "rate limited", logger)
+	}
+	return err
+}
+
+// strike counts a rejection against the session's address, logging the
+// ban it may earn.
+func strike(guard *access.Guard, sess ssh.Session, reason string, logger *slog.Logger) {
+	banned, err := guard.Strike(access.IP(sess.RemoteAddr()), reason)
+	if err != nil {
+		logger.Error("could not save bans", "err", err)
 	}
-	if v > hi {
-		return hi
+	if banned {
+		metrics.Bans.Inc()
+		logger.Warn("address banned", "reason", reason)
 	}
-	return v
+}
+
+// accessMiddleware caps how many sessions one address may hold at once.
+// Denied and banned addresses never get this far; acceptConn drops them.
+func accessMiddleware(guard *access.Guard, logger *slog.Logger, ipMode string) wish.Middleware {
+	return func(next ssh.Handler) ssh.Handler {
+		return func(sess ssh.Session) {
+			release, err := guard.Acquire(access.IP(sess.RemoteAddr()))
+			if err != nil {
+				metrics.Rejections.WithLabelValues("per_ip").Inc()
+				var attrs []any
+				if addr := remoteAddr(sess.RemoteAddr(), ipMode); addr != "" {
+					attrs = append(attrs, "remote", addr)
+				}
+				sessionLogger(sess, logger).Warn("rejected: too many sessions from one address", attrs...)
+				wish.Fatalln(sess, err.Error())
+				return
+			}
+			defer release()
+			next(sess)
+		}
+	}
+}
+
+// acceptConn drops connections from denied or banned addresses before the
+// SSH handshake, so they cost next to nothing.
+func acceptConn(guard *access.Guard, logger *slog.Logger) ssh.Option {
+	return func(s *ssh.Server) error {
+		s.ConnCallback = func(ctx ssh.Context, conn net.Conn) net.Conn {
+			err := guard.Check(access.IP(conn.RemoteAddr()))
+			if err == nil {
+				return conn
+			}
+			var banned *access.BannedError
+			if errors.As(err, &banned) {
+				metrics.Rejections.WithLabelValues("banned").Inc()
+			} else {
+				metrics.Rejections.WithLabelValues("denied").Inc()
+			}
+			logger.Debug("connection dropped", "reason", err)
+			return nil
+		}
+		return nil
+	}
+}
+
+// nodeKey is the session context key holding the caller's *nodes.Node.
+type nodeKey struct{}
+
+// nodeMiddleware assigns each session a node number for its lifetime and
+// records the call in the last callers log once the session ends, unless
+// the caller opted out. When every node is taken the caller gets the busy
+// screen instead, waiting in line for a node if queue is set.
+func nodeMiddleware(reg *nodes.Registry, calls *callers.Log, queue bool, logger *slog.Logger) wish.Middleware {
+	return func(next ssh.Handler) ssh.Handler {
+		return func(sess ssh.Session) {
+			// The sysop console is not a call and takes no node.
+			if wantsAdmin(sess) {
+				next(sess)
+				return
+			}
+			id := keyID(sess)
+			var ip string
+			if addr := access.IP(sess.RemoteAddr()); addr != nil {
+				ip = addr.String()
+			}
+			handle := sanitizeUsername(sess.User())
+			n, err := reg.Join(handle, id != "", ip)
+			if errors.Is(err, nodes.ErrDraining) {
+				sessionLogger(sess, logger).Info("rejected: server going down")
+				wish.Fatalln(sess, "terminull is going down. Please call back later.")
+				return
+			}
+			if errors.Is(err, nodes.ErrFull) {
+				metrics.Rejections.WithLabelValues("busy").Inc()
+				sessionLogger(sess, logger).Info("all nodes busy", "queued", queue)
+				if n = waitForNode(sess, reg, handle, id != "", ip, queue); n == nil {
+					return
+				}
+			}
+			defer reg.Leave(n)
+			n.SetUnlisted(calls.OptedOut(id))
+			sess.Context().SetValue(nodeKey{}, n)
+
+			next(sess)
+
+			if n.Unlisted() {
+				return
+			}
+			err = calls.Record(callers.Call{
+				Handle:    n.Handle,
+				Node:      n.Number,
+				Connected: n.Connected,
+				Duration:  time.Since(n.Connected).Round(time.Second),
+				Member:    n.Member,
+			})
+			if err != nil {
+				sessionLogger(sess, logger).Error("could not record call", "err", err)
+			}
+		}
+	}
+}
+
+// kickGrace is how long a kicked caller sees the notice before the
+// connection is dropped. It also covers callers inside a door, whose
+// program cannot process the kick until the door exits.
+const kickGrace = 3 * time.Second
+
+// attachNode lets the registry reach the program serving n.
+func attachNode(reg *nodes.Registry, n *nodes.Node, sess ssh.Session, p *tea.Program) {
+	send := func(msg any) { p.Send(msg) }
+	reg.Attach(n, send, func() {
+		go p.Send(types.KickMsg{})
+		time.AfterFunc(kickGrace, func() {
+			if conn, ok := sess.Context().Value(ssh.ContextKeyConn).(gossh.Conn); ok {
+				conn.Close()
+			}
+		})
+	})
+}
+
+// keyID returns the SHA256 fingerprint of the session's public key, or ""
+// for guests who authenticated without one.
+func keyID(sess ssh.Session) string {
+	if k := sess.PublicKey(); k != nil {
+		return gossh.FingerprintSHA256(k)
+	}
+	return ""
+}
+
+// timeoutGrace is how long the server waits past a session timeout before
+// dropping a connection that has not closed itself.
+const timeoutGrace = 30 * time.Second
+
+// backstop returns the server-side limit for a session timeout of d.
+func backstop(d time.Duration) time.Duration {
+	if d <= 0 {
+		return 0
+	}
+	return d + timeoutGrace
 }
 
 func main() {
@@ -84,21 +280,160 @@ func main() {
 	lipgloss.SetColorProfile(termenv.ANSI256)
 	lipgloss.SetHasDarkBackground(true)
 
-	cfg := LoadConfig()
+	cfg, err := LoadConfig()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "terminull-ssh: bad configuration:")
+		for _, line := range strings.Split(err.Error(), "\n") {
+			fmt.Fprintln(os.Stderr, "  "+line)
+		}
+		os.Exit(2)
+	}
+	if cfg.PrintConfig {
+		out, err := cfg.YAML()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "terminull-ssh: %v\n", err)
+			os.Exit(1)
+		}
+		os.Stdout.Write(out)
+		return
+	}
+	theme.MaxWidth = cfg.ContentWidth
+	layout.MinWidth, layout.MaxWidth = cfg.MinCols, cfg.MaxCols
+	layout.MinHeight, layout.MaxHeight = cfg.MinRows, cfg.MaxRows
 
-	// Load content at startup
-	store := content.LoadStore(cfg.ContentDir)
+	logger, err := newLogger(cfg, os.Stderr)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "terminull-ssh: %v\n", err)
+		os.Exit(2)
+	}
+	slog.SetDefault(logger)
+
+	// `terminull-ssh <command> [args]` talks to a running server, except
+	// play, which replays a recording locally.
+	if args := flag.Args(); len(args) > 0 {
+		if args[0] == "play" {
+			if err := playRecording(args[1:]); err != nil {
+				fmt.Fprintf(os.Stderr, "terminull-ssh: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+		if !controlCommands[args[0]] {
+			fmt.Fprintln(os.Stderr, "usage: terminull-ssh [flags] [broadcast <message> | ban <ip> [duration] | unban <ip> | bans | play <file> [speed]]")
+			os.Exit(2)
+		}
+		if err := sendControl(cfg.ControlSock, strings.Join(args, " ")); err != nil {
+			fatal(logger, args[0]+" failed", "err", err)
+		}
+		return
+	}
+
+	if cfg.HostKey != "" {
+		logger.Warn("host_key is deprecated; list the key first in host_keys instead", "host_key", cfg.HostKey, "host_keys", cfg.HostKeys)
+	}
+
+	started := time.Now()
+
+	// Load content at startup, warning of anything over the render budget.
+	budget := render.Budget{Timeout: cfg.RenderTimeout, MaxSize: cfg.RenderMaxSize, Overruns: render.NewOverruns()}
+	library := content.NewLibrary(cfg.ContentDir, logger.With("component", "content"),
+		screens.CheckRenderBudget(cfg.SiteURL, budget))
+
+	admins, err := loadAdminKeys(cfg.AdminKeys)
+	if err != nil {
+		fatal(logger, "could not load admin keys", "err", err)
+	}
+	if len(admins) > 0 {
+		logger.Info("admin keys loaded", "keys", len(admins), "path", cfg.AdminKeys)
+	}
+
+	doors, err := door.Load(cfg.DoorsFile)
+	if err != nil {
+		fatal(logger, "could not load doors", "err", err)
+	}
+	if doors.Len() > 0 {
+		logger.Info("doors loaded", "doors", doors.Len(), "path", cfg.DoorsFile)
+	}
+
+	wall, err := oneliners.Open(filepath.Join(cfg.DataDir, "oneliners.json"))
+	if err != nil {
+		fatal(logger, "could not load oneliners", "err", err)
+	}
+
+	calls, err := callers.Open(filepath.Join(cfg.DataDir, "callers.json"))
+	if err != nil {
+		fatal(logger, "could not load last callers", "err", err)
+	}
+
+	booth, err := votes.Open(filepath.Join(cfg.DataDir, "votes.json"))
+	if err != nil {
+		fatal(logger, "could not load poll tallies", "err", err)
+	}
+
+	scores, err := ctf.Open(filepath.Join(cfg.DataDir, "ctf.json"))
+	if err != nil {
+		fatal(logger, "could not load CTF solves", "err", err)
+	}
+
+	allow, err := access.ParseCIDRs(cfg.Allow)
+	if err != nil {
+		fatal(logger, "bad allow list", "err", err)
+	}
+	deny, err := access.ParseCIDRs(cfg.Deny)
+	if err != nil {
+		fatal(logger, "bad deny list", "err", err)
+	}
+	guard, err := access.Open(filepath.Join(cfg.DataDir, "bans.json"), access.Config{
+		Allow:    allow,
+		Deny:     deny,
+		MaxPerIP: cfg.MaxPerIP,
+		Strikes:  cfg.BanStrikes,
+		Window:   cfg.BanWindow,
+		BanFor:   cfg.BanFor,
+		Track:    cfg.RateTrack,
+	})
+	if err != nil {
+		fatal(logger, "could not load bans", "err", err)
+	}
+
+	svc := ui.Services{
+		Content: library,
+		Doors:   doors,
+		Wall:    wall,
+		Callers: calls,
+		Votes:   booth,
+		Scores:  scores,
+		Access:  guard,
+		SiteURL: cfg.SiteURL,
+		Renders: render.NewCache(cfg.RenderCache),
+		Budget:  budget,
+		Timeouts: ui.Timeouts{
+			Idle: cfg.IdleTimeout,
+			Max:  cfg.MaxTimeout,
+		},
+	}
+	nodeReg := nodes.NewRegistry(cfg.MaxNodes, cfg.Reserved)
+
+	hostKeys, err := loadHostKeys(splitList(cfg.HostKeys), splitList(cfg.NextHostKeys), logger)
+	if err != nil {
+		fatal(logger, "could not load host keys", "err", err)
+	}
 
-	// Rate limiter: 1 conn/sec sustained, burst of 10, track up to 256 IPs
-	limiter := ratelimiter.NewRateLimiter(rate.Every(time.Second), 10, 256)
+	limiter := countingLimiter{ratelimiter.NewRateLimiter(rate.Every(cfg.RateInterval), cfg.RateBurst, cfg.RateTrack), guard, logger, cfg.LogIP}
 
-	s, err := wish.NewServer(
-		wish.WithAddress(fmt.Sprintf("%s:%d", cfg.Host, cfg.Port)),
-		wish.WithHostKeyPath(cfg.HostKeyPath),
-		wish.WithIdleTimeout(10*time.Minute),
-		wish.WithMaxTimeout(2*time.Hour),
+	opts := []ssh.Option{
+		hostKeys.option(),
+		// Anyone may call. Callers who offer a key become members with a
+		// stable identity; everyone else falls through as a guest.
+		wish.WithPublicKeyAuth(func(ssh.Context, ssh.PublicKey) bool { return true }),
+		wish.WithKeyboardInteractiveAuth(func(ssh.Context, gossh.KeyboardInteractiveChallenge) bool { return true }),
+		acceptConn(guard, logger),
+		// Sessions hang themselves up with a goodbye when they time out;
+		// these catch anything that does not, like the sysop console.
+		wish.WithIdleTimeout(backstop(cfg.IdleTimeout)),
+		wish.WithMaxTimeout(backstop(cfg.MaxTimeout)),
 		wish.WithMiddleware(
-			bubbletea.Middleware(func(sess ssh.Session) (tea.Model, []tea.ProgramOption) {
+			bubbletea.MiddlewareWithProgramHandler(func(sess ssh.Session) *tea.Program {
 				pty, _, _ := sess.Pty()
 				w := pty.Window.Width
 				h := pty.Window.Height
@@ -108,37 +443,118 @@ func main() {
 				if h == 0 {
 					h = 24
 				}
-				w = clamp(w, 40, 300)
-				h = clamp(h, 10, 100)
-				username := sanitizeUsername(sess.User())
-				model := ui.NewApp(store, w, h, username, cfg.SiteURL)
-				return model, []tea.ProgramOption{tea.WithAltScreen()}
-			}),
-			usernameGuard(),
+				// Signals belong to the server, which drains sessions on
+				// SIGTERM; left to itself every program would quit at once.
+				opts := append([]tea.ProgramOption{tea.WithAltScreen(), tea.WithoutSignalHandler()}, bubbletea.MakeOptions(sess)...)
+
+				if wantsAdmin(sess) {
+					if !admins.allowed(sess) {
+						wish.Fatalln(sess, "permission denied")
+						return nil
+					}
+					sessionLogger(sess, logger).Info("sysop console opened", "key", keyID(sess))
+					return tea.NewProgram(ui.NewAdmin(svc, nodeReg, started, w, h), opts...)
+				}
+
+				caller := ui.Session{
+					Username: sanitizeUsername(sess.User()),
+					KeyID:    keyID(sess),
+					Terminal: sessionOutput(sess),
+				}
+				n, _ := sess.Context().Value(nodeKey{}).(*nodes.Node)
+				caller.Node = n
+				num := 0
+				if n != nil {
+					num = n.Number
+				}
+				if rec := startRecording(sess, cfg, num, w, h, logger); rec != nil {
+					caller.Recording = true
+					opts = append(opts, recordOptions(sess, rec)...)
+				}
+				p := tea.NewProgram(ui.NewApp(svc, caller, w, h), opts...)
+				if n != nil {
+					attachNode(nodeReg, n, sess, p)
+				}
+				return p
+			}, termenv.Ascii),
+			nodeMiddleware(nodeReg, calls, cfg.Queue, logger),
+			usernameGuard(guard, logger),
 			activeterm.Middleware(),
-			logging.Middleware(),
 			ratelimiter.Middleware(limiter),
+			accessMiddleware(guard, logger, cfg.LogIP),
+			// Outside the limits, so their rejections carry the session ID.
+			logMiddleware(logger, cfg.LogIP),
+			sessionMetrics(),
+			hostKeys.middleware(),
 		),
-	)
+	}
+	// Doors need a real PTY so external programs see a terminal.
+	if doors.Len() > 0 {
+		opts = append(opts, ssh.AllocatePty())
+	}
+
+	if cfg.ControlSock != "" {
+		l, err := listenControl(cfg.ControlSock)
+		if err != nil {
+			fatal(logger, "could not open control socket", "err", err)
+		}
+		defer l.Close()
+		go serveControl(l, nodeReg, guard, logger.With("component", "control"))
+		logger.Info("control socket listening", "path", cfg.ControlSock)
+	}
+
+	if cfg.MetricsAddr != "" {
+		go func() {
+			if err := metrics.Serve(cfg.MetricsAddr); err != nil {
+				fatal(logger, "metrics server error", "err", err)
+			}
+		}()
+		logger.Info("serving metrics", "url", "http://"+cfg.MetricsAddr+"/metrics")
+	}
+
+	s, err := wish.NewServer(opts...)
 	if err != nil {
-		log.Fatalf("could not create SSH server: %v", err)
+		fatal(logger, "could not create SSH server", "err", err)
+	}
+
+	lns, err := openListeners(cfg, logger)
+	if err != nil {
+		fatal(logger, "could not listen", "err", err)
+	}
+	if cfg.ProxyFrom != "" {
+		trusted, unix, _ := parseProxyFrom(cfg.ProxyFrom)
+		for i, ln := range lns {
+			lns[i] = proxyListener(ln, trusted, unix)
+		}
 	}
 
 	done := make(chan os.Signal, 1)
 	signal.Notify(done, os.Interrupt, syscall.SIGTERM)
 
-	log.Printf("Starting terminull SSH BBS on %s:%d", cfg.Host, cfg.Port)
-	go func() {
-		if err := s.ListenAndServe(); err != nil {
-			log.Fatalf("SSH server error: %v", err)
-		}
-	}()
+	for _, ln := range lns {
+		logger.Info("starting terminull SSH BBS", "addr", ln.Addr().String(), "proxy_protocol", cfg.ProxyFrom != "")
+		go func() {
+			// Closing the listener to drain is not an error.
+			if err := s.Serve(ln); err != nil && !errors.Is(err, ssh.ErrServerClosed) && !errors.Is(err, net.ErrClosed) {
+				fatal(logger, "SSH server error", "err", err)
+			}
+		}()
+	}
+	notify(daemon.SdNotifyReady, logger)
 
 	<-done
-	log.Println("Shutting down...")
+	logger.Info("shutting down")
+	notify(daemon.SdNotifyStopping, logger)
+	if forced := drain(lns, nodeReg, cfg.Drain, done, logger); forced || nodeReg.Count() > 0 {
+		s.Close()
+		return
+	}
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 	if err := s.Shutdown(ctx); err != nil {
-		log.Fatalf("shutdown error: %v", err)
+		// Whoever is still connected (the sysop console, a stuck door)
+		// gets cut off.
+		logger.Warn("shutdown incomplete", "err", err)
+		s.Close()
 	}
 }
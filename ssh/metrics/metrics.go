@@ -0,0 +1,122 @@
+// Package metrics exposes aggregate server counters in the Prometheus
+// text format. Nothing here is per caller: no handles, keys or addresses
+// ever become labels.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var registry = prometheus.NewRegistry()
+
+var (
+	// ActiveSessions is the number of SSH sessions currently open.
+	ActiveSessions = register(prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "terminull_sessions_active",
+		Help: "SSH sessions currently open.",
+	}))
+
+	// Connections counts every SSH session opened, including rejected ones.
+	Connections = register(prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "terminull_connections_total",
+		Help: "SSH sessions opened since start.",
+	}))
+
+	// Rejections counts sessions turned away, by reason.
+	Rejections = register(prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "terminull_rejections_total",
+		Help: "Sessions rejected before reaching the BBS, by reason.",
+	}, []string{"reason"}))
+
+	// Bans counts automatic bans handed out.
+	Bans = register(prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "terminull_bans_total",
+		Help: "Addresses banned automatically for repeated rejections.",
+	}))
+
+	// Navigations counts screens opened, by screen type.
+	Navigations = register(prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "terminull_navigations_total",
+		Help: "Screens opened, by screen type.",
+	}, []string{"screen"}))
+
+	// ArticleOpens counts article reads, by volume and slug.
+	ArticleOpens = register(prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "terminull_article_opens_total",
+		Help: "Articles opened, by volume and slug.",
+	}, []string{"volume", "slug"}))
+
+	// RenderSeconds times Glamour markdown rendering, by kind of content.
+	RenderSeconds = register(prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "terminull_render_duration_seconds",
+		Help:    "Time spent rendering markdown with Glamour.",
+		Buckets: []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"kind"}))
+
+	// RenderCache counts article renderings taken from the shared cache
+	// (hit) or rendered afresh (miss).
+	RenderCache = register(prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "terminull_render_cache_total",
+		Help: "Article renderings served from the cache or rendered, by result.",
+	}, []string{"result"}))
+
+	// ContentLoads counts content (re)loads.
+	ContentLoads = register(prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "terminull_content_loads_total",
+		Help: "Times the content directory was loaded.",
+	}))
+
+	// ContentItems is the size of the loaded content, by kind.
+	ContentItems = register(prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "terminull_content_items",
+		Help: "Items in the most recently loaded content, by kind.",
+	}, []string{"kind"}))
+
+	// ContentWarnings is the number of warnings from the latest load.
+	ContentWarnings = register(prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "terminull_content_warnings",
+		Help: "Warnings raised by the most recent content load.",
+	}))
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// register registers c with the package registry and returns it.
+func register[C prometheus.Collector](c C) C {
+	registry.MustRegister(c)
+	return c
+}
+
+// ObserveRender records a markdown render of kind that began at start.
+func ObserveRender(kind string, start time.Time) {
+	RenderSeconds.WithLabelValues(kind).Observe(time.Since(start).Seconds())
+}
+
+// ArticleOpened counts a read of the article slug in volume vol.
+func ArticleOpened(vol int, slug string) {
+	ArticleOpens.WithLabelValues(strconv.Itoa(vol), slug).Inc()
+}
+
+// Serve exposes /metrics on addr. It only returns on failure.
+func Serve(addr string) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	return srv.ListenAndServe()
+}
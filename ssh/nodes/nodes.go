@@ -0,0 +1,209 @@
+package nodes
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Node is a single caller slot, numbered from 1 like the lines on a
+// classic multi-node BBS. Exported fields are fixed once the node is
+// joined and may be read without locking.
+type Node struct {
+	Number    int
+	Handle    string
+	Member    bool // caller authenticated with a public key
+	Connected time.Time
+	IP        string // remote address, held in memory for sysop bans and never recorded
+
+	unlisted atomic.Bool
+}
+
+// SetUnlisted opts the caller in or out of the last callers list.
+func (n *Node) SetUnlisted(v bool) { n.unlisted.Store(v) }
+
+// Unlisted reports whether the caller opted out of the last callers list.
+func (n *Node) Unlisted() bool { return n.unlisted.Load() }
+
+var (
+	// ErrFull is returned by Join when every node the caller may use is taken.
+	ErrFull = errors.New("all nodes busy")
+
+	// ErrDraining is returned by Join once the server is going down.
+	ErrDraining = errors.New("server going down")
+)
+
+// hooks reach into a live session from outside it.
+type hooks struct {
+	send func(msg any) // deliver a message to the session's program
+	kick func()        // disconnect the session
+}
+
+// Registry hands out node numbers to live sessions. It is shared by all
+// SSH sessions and safe for concurrent use.
+type Registry struct {
+	max      int // total nodes; 0 is unlimited
+	reserved int // nodes only members may take
+
+	mu      sync.Mutex
+	nodes   map[int]*Node
+	hooks   map[*Node]hooks
+	line    []*Ticket
+	changed chan struct{} // closed and replaced whenever a node or place in line frees up
+	down    any           // set once draining: sent to every session as it becomes reachable
+}
+
+// NewRegistry creates an empty node registry with max nodes, of which
+// reserved are kept for members. A max of 0 means unlimited.
+func NewRegistry(max, reserved int) *Registry {
+	return &Registry{
+		max:      max,
+		reserved: reserved,
+		nodes:    make(map[int]*Node),
+		hooks:    make(map[*Node]hooks),
+		changed:  make(chan struct{}),
+	}
+}
+
+// Max returns the number of nodes, 0 if unlimited.
+func (r *Registry) Max() int { return r.max }
+
+// Reserved returns the number of nodes kept for members.
+func (r *Registry) Reserved() int { return r.reserved }
+
+// room reports whether a caller may take a node. Caller must hold r.mu.
+func (r *Registry) room(member bool) bool {
+	if r.max <= 0 {
+		return true
+	}
+	limit := r.max
+	if !member {
+		limit -= r.reserved
+	}
+	return len(r.nodes) < limit
+}
+
+// Join assigns the lowest free node number to a new caller, or returns
+// ErrFull. Callers waiting in line get first pick of free nodes.
+func (r *Registry) Join(handle string, member bool, ip string) (*Node, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.down != nil {
+		return nil, ErrDraining
+	}
+	if !r.room(member) || r.waiting(len(r.line)) {
+		return nil, ErrFull
+	}
+	return r.join(handle, member, ip), nil
+}
+
+// join takes a node. Caller must hold r.mu and have checked room.
+func (r *Registry) join(handle string, member bool, ip string) *Node {
+	num := 1
+	for r.nodes[num] != nil {
+		num++
+	}
+	n := &Node{
+		Number:    num,
+		Handle:    handle,
+		Member:    member,
+		Connected: time.Now(),
+		IP:        ip,
+	}
+	r.nodes[num] = n
+	return n
+}
+
+// Leave frees the node held by n.
+func (r *Registry) Leave(n *Node) {
+	if n == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.nodes[n.Number] == n {
+		delete(r.nodes, n.Number)
+		r.notify()
+	}
+	delete(r.hooks, n)
+}
+
+// Attach registers how to message and disconnect the session on n. Until
+// it is called the node can be listed but not reached.
+func (r *Registry) Attach(n *Node, send func(msg any), kick func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.nodes[n.Number] == n {
+		r.hooks[n] = hooks{send: send, kick: kick}
+		if r.down != nil {
+			go send(r.down)
+		}
+	}
+}
+
+// Kick disconnects the caller on node num. It reports whether a
+// reachable session was found.
+func (r *Registry) Kick(num int) bool {
+	r.mu.Lock()
+	h, ok := r.hooks[r.nodes[num]]
+	r.mu.Unlock()
+	if !ok {
+		return false
+	}
+	h.kick()
+	return true
+}
+
+// Drain stops handing out nodes as the server goes down, and sends down
+// to every session, those reached later included, without waiting for
+// them to receive it. Callers waiting in line are woken to find out.
+func (r *Registry) Drain(down any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.down = down
+	for _, h := range r.hooks {
+		go h.send(down)
+	}
+	r.notify()
+}
+
+// Draining reports whether the server is going down.
+func (r *Registry) Draining() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.down != nil
+}
+
+// Broadcast delivers msg to every reachable session without waiting for
+// them to receive it, returning how many sessions it was sent to.
+func (r *Registry) Broadcast(msg any) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, h := range r.hooks {
+		go h.send(msg)
+	}
+	return len(r.hooks)
+}
+
+// Active returns all occupied nodes, sorted by number.
+func (r *Registry) Active() []*Node {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	out := make([]*Node, 0, len(r.nodes))
+	for _, n := range r.nodes {
+		out = append(out, n)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Number < out[j].Number })
+	return out
+}
+
+// Count returns the number of occupied nodes.
+func (r *Registry) Count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.nodes)
+}
@@ -0,0 +1,84 @@
+package nodes
+
+// Ticket is a caller's place in line for a node.
+type Ticket struct {
+	member bool
+}
+
+// Enqueue puts a caller at the back of the line.
+func (r *Registry) Enqueue(member bool) *Ticket {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t := &Ticket{member: member}
+	r.line = append(r.line, t)
+	return t
+}
+
+// Dequeue takes t out of the line, whether or not it got a node.
+func (r *Registry) Dequeue(t *Ticket) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if i := r.index(t); i >= 0 {
+		r.line = append(r.line[:i], r.line[i+1:]...)
+		r.notify()
+	}
+}
+
+// Position returns t's 1-based place in line, or 0 if it is not in line.
+func (r *Registry) Position(t *Ticket) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.index(t) + 1
+}
+
+// TryJoin gives t a node if one is free and nobody ahead of t in line
+// could take it first. On success t leaves the line. Nobody gets a node
+// once the server is draining.
+func (r *Registry) TryJoin(t *Ticket, handle, ip string) (*Node, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := r.index(t)
+	if i < 0 || r.down != nil || !r.room(t.member) || r.waiting(i) {
+		return nil, false
+	}
+	r.line = append(r.line[:i], r.line[i+1:]...)
+	r.notify()
+	return r.join(handle, t.member, ip), true
+}
+
+// Changed returns a channel that is closed the next time a node or a
+// place in line frees up.
+func (r *Registry) Changed() <-chan struct{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.changed
+}
+
+// waiting reports whether any of the first n callers in line could take
+// a free node. Members may pass guests when only reserved nodes are
+// free. Caller must hold r.mu.
+func (r *Registry) waiting(n int) bool {
+	for _, t := range r.line[:n] {
+		if r.room(t.member) {
+			return true
+		}
+	}
+	return false
+}
+
+// index returns t's index in line, or -1. Caller must hold r.mu.
+func (r *Registry) index(t *Ticket) int {
+	for i, u := range r.line {
+		if u == t {
+			return i
+		}
+	}
+	return -1
+}
+
+// notify wakes everyone watching Changed. Caller must hold r.mu.
+func (r *Registry) notify() {
+	close(r.changed)
+	r.changed = make(chan struct{})
+}
@@ -0,0 +1,178 @@
+package oneliners
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"terminull-ssh/content"
+)
+
+const (
+	// MaxLen is the maximum length of a oneliner in runes.
+	MaxLen = 64
+
+	// keep is how many oneliners are persisted; older ones roll off.
+	keep = 50
+
+	// cooldown is how long a key must wait between oneliners.
+	cooldown = time.Hour
+)
+
+var (
+	// ErrNoKey is returned when a guest without an SSH key tries to post.
+	ErrNoKey = errors.New("connect with an SSH key to leave a oneliner")
+
+	// ErrCooldown is returned when a key posts again within the cooldown.
+	ErrCooldown = errors.New("one oneliner per hour, please")
+
+	// ErrEmpty is returned when nothing printable is left after cleaning.
+	ErrEmpty = errors.New("oneliner is empty")
+)
+
+// Entry is a single message on the wall.
+type Entry struct {
+	Handle string    `json:"handle"`
+	Text   string    `json:"text"`
+	Time   time.Time `json:"time"`
+}
+
+// wallFile is the on-disk layout of the wall.
+type wallFile struct {
+	Entries  []Entry              `json:"entries"`
+	LastPost map[string]time.Time `json:"last_post"` // key fingerprint → last post, while within the cooldown
+}
+
+// Wall is the persisted oneliner wall shared by all sessions.
+type Wall struct {
+	path string
+
+	mu       sync.Mutex
+	entries  []Entry
+	lastPost map[string]time.Time // key fingerprint → last post
+}
+
+// Open loads the wall stored at path, starting empty if it does not exist.
+func Open(path string) (*Wall, error) {
+	w := &Wall{path: path, lastPost: make(map[string]time.Time)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return w, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	// Walls saved before the cooldown was persisted are a bare list.
+	if strings.HasPrefix(strings.TrimSpace(string(data)), "[") {
+		if err := json.Unmarshal(data, &w.entries); err != nil {
+			return nil, err
+		}
+		return w, nil
+	}
+	var f wallFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, err
+	}
+	w.entries = f.Entries
+	for k, t := range f.LastPost {
+		w.lastPost[k] = t
+	}
+	return w, nil
+}
+
+// Recent returns up to n of the newest entries, newest first.
+func (w *Wall) Recent(n int) []Entry {
+	if w == nil {
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var out []Entry
+	for i := len(w.entries) - 1; i >= 0 && len(out) < n; i-- {
+		out = append(out, w.entries[i])
+	}
+	return out
+}
+
+// Post cleans text and appends it to the wall on behalf of keyID.
+func (w *Wall) Post(handle, keyID, text string) error {
+	if keyID == "" {
+		return ErrNoKey
+	}
+	text = Clean(text)
+	if text == "" {
+		return ErrEmpty
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	now := time.Now()
+	if last, ok := w.lastPost[keyID]; ok && now.Sub(last) < cooldown {
+		return ErrCooldown
+	}
+
+	// Keep the entries as they were to put back if the wall cannot be
+	// saved; any earlier post by keyID is past the cooldown already.
+	entries := w.entries
+	w.entries = append(w.entries, Entry{Handle: handle, Text: text, Time: now})
+	if len(w.entries) > keep {
+		w.entries = w.entries[len(w.entries)-keep:]
+	}
+	w.lastPost[keyID] = now
+	if err := w.save(now); err != nil {
+		w.entries = entries
+		delete(w.lastPost, keyID)
+		return err
+	}
+	return nil
+}
+
+// save writes the wall atomically, forgetting posts older than the
+// cooldown. Caller must hold w.mu.
+func (w *Wall) save(now time.Time) error {
+	for k, t := range w.lastPost {
+		if now.Sub(t) >= cooldown {
+			delete(w.lastPost, k)
+		}
+	}
+	data, err := json.MarshalIndent(wallFile{Entries: w.entries, LastPost: w.lastPost}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(w.path), 0o755); err != nil {
+		return err
+	}
+	tmp := w.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, w.path)
+}
+
+// Clean strips escape sequences and non-printables, folds the text onto
+// a single line and truncates it to MaxLen runes.
+func Clean(s string) string {
+	s = content.SanitizeLine(s)
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case unicode.IsSpace(r):
+			b.WriteRune(' ')
+		case unicode.IsPrint(r):
+			b.WriteRune(r)
+		}
+	}
+	s = strings.Join(strings.Fields(b.String()), " ")
+	if r := []rune(s); len(r) > MaxLen {
+		s = string(r[:MaxLen])
+	}
+	return s
+}
@@ -0,0 +1,278 @@
+// Package record writes sessions to asciicast v2 files, for demos and for
+// reproducing rendering bugs, and plays them back.
+//
+// The format is a JSON header line followed by one JSON array per event:
+// [seconds, "o", output] or [seconds, "r", "COLSxROWS"].
+// See https://docs.asciinema.org/manual/asciicast/v2/.
+package record
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Ext is the file extension of recordings.
+const Ext = ".cast"
+
+// Header is the first line of an asciicast v2 file.
+type Header struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp"`
+	Title     string            `json:"title,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// Recorder appends one session's output and resizes to a recording. It
+// is safe for concurrent use. Write errors stop the recording rather than
+// the session.
+type Recorder struct {
+	path  string
+	start time.Time
+
+	mu      sync.Mutex
+	f       *os.File
+	w       *bufio.Writer
+	pending []byte // an incomplete UTF-8 sequence held for the next write
+	failed  error
+}
+
+// Start creates a recording named name in dir for a terminal of the
+// given size. Recordings are readable only by the server's user.
+func Start(dir, name string, width, height int, term, title string) (*Recorder, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	path := filepath.Join(dir, name+Ext)
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Recorder{path: path, start: time.Now(), f: f, w: bufio.NewWriter(f)}
+	h := Header{
+		Version:   2,
+		Width:     width,
+		Height:    height,
+		Timestamp: r.start.Unix(),
+		Title:     title,
+		Env:       map[string]string{"TERM": term},
+	}
+	if err := json.NewEncoder(r.w).Encode(h); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+// Path returns the file the recording is written to.
+func (r *Recorder) Path() string { return r.path }
+
+// Write records p as output. It always reports success so that a failed
+// recording never disturbs the session it is attached to.
+func (r *Recorder) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	data := append(r.pending, p...)
+	cut := completeUTF8(data)
+	r.pending = append([]byte(nil), data[cut:]...)
+	if cut > 0 {
+		r.event("o", string(data[:cut]))
+	}
+	return len(p), nil
+}
+
+// Resize records the terminal changing size.
+func (r *Recorder) Resize(width, height int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.event("r", fmt.Sprintf("%dx%d", width, height))
+}
+
+// event appends one event. Caller must hold r.mu.
+func (r *Recorder) event(kind, data string) {
+	if r.failed != nil || r.f == nil {
+		return
+	}
+	t := time.Since(r.start).Seconds()
+	line, err := json.Marshal([]any{float64(int64(t*1e6)) / 1e6, kind, data})
+	if err == nil {
+		_, err = r.w.Write(append(line, '\n'))
+	}
+	if err != nil {
+		r.failed = err
+	}
+}
+
+// Close finishes the recording.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.f == nil {
+		return nil
+	}
+	if len(r.pending) > 0 {
+		r.event("o", string(r.pending))
+		r.pending = nil
+	}
+	err := errors.Join(r.failed, r.w.Flush(), r.f.Close())
+	r.f = nil
+	return err
+}
+
+// completeUTF8 returns the length of the longest prefix of p that does not
+// end partway through a UTF-8 sequence, so a rune split across two writes
+// is not mangled in the JSON.
+func completeUTF8(p []byte) int {
+	for i := len(p) - 1; i >= 0 && i >= len(p)-utf8.UTFMax; i-- {
+		if !utf8.RuneStart(p[i]) {
+			continue
+		}
+		if !utf8.FullRune(p[i:]) {
+			return i
+		}
+		break
+	}
+	return len(p)
+}
+
+// Tee returns a writer that sends everything to w and to r. If w is a
+// terminal file the result still is one, as far as callers checking for
+// an *os.File's methods can tell, and Terminal returns the file itself.
+func (r *Recorder) Tee(w io.Writer) io.Writer {
+	if f, ok := w.(*os.File); ok {
+		return &fileTee{File: f, rec: r}
+	}
+	return &tee{w: w, rec: r}
+}
+
+type tee struct {
+	w   io.Writer
+	rec *Recorder
+}
+
+func (t *tee) Write(p []byte) (int, error) {
+	n, err := t.w.Write(p)
+	_, _ = t.rec.Write(p[:n])
+	return n, err
+}
+
+type fileTee struct {
+	*os.File
+	rec *Recorder
+}
+
+func (t *fileTee) Write(p []byte) (int, error) {
+	n, err := t.File.Write(p)
+	_, _ = t.rec.Write(p[:n])
+	return n, err
+}
+
+// Terminal returns the terminal being recorded, for programs that need
+// the file itself.
+func (t *fileTee) Terminal() *os.File { return t.File }
+
+// Prune deletes all but the newest keep recordings in dir.
+func Prune(dir string, keep int) (removed int, err error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	type cast struct {
+		path string
+		mod  time.Time
+	}
+	var casts []cast
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), Ext) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		casts = append(casts, cast{filepath.Join(dir, e.Name()), info.ModTime()})
+	}
+	if len(casts) <= keep {
+		return 0, nil
+	}
+	sort.Slice(casts, func(i, j int) bool { return casts[i].mod.After(casts[j].mod) })
+
+	var errs []error
+	for _, c := range casts[keep:] {
+		if err := os.Remove(c.path); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		removed++
+	}
+	return removed, errors.Join(errs...)
+}
+
+// Player replays a recording.
+type Player struct {
+	Header Header
+	sc     *bufio.Scanner
+}
+
+// NewPlayer reads the header of the recording in in.
+func NewPlayer(in io.Reader) (*Player, error) {
+	sc := bufio.NewScanner(in)
+	sc.Buffer(make([]byte, 64*1024), 16<<20)
+	if !sc.Scan() {
+		return nil, errors.Join(errors.New("empty recording"), sc.Err())
+	}
+	p := &Player{sc: sc}
+	if err := json.Unmarshal(sc.Bytes(), &p.Header); err != nil || p.Header.Version != 2 {
+		return nil, errors.New("not an asciicast v2 recording")
+	}
+	return p, nil
+}
+
+// Play writes the recorded output to out with its original timing, sped
+// up by speed. Pauses longer than maxIdle are cut short (0 keeps them).
+func (p *Player) Play(out io.Writer, speed float64, maxIdle time.Duration) error {
+	if speed <= 0 {
+		speed = 1
+	}
+	var last float64
+	for p.sc.Scan() {
+		var ev []any
+		if err := json.Unmarshal(p.sc.Bytes(), &ev); err != nil || len(ev) != 3 {
+			return fmt.Errorf("bad event: %.60s", p.sc.Bytes())
+		}
+		t, _ := ev[0].(float64)
+		kind, _ := ev[1].(string)
+		data, _ := ev[2].(string)
+
+		wait := t - last
+		last = t
+		if maxIdle > 0 && wait > maxIdle.Seconds() {
+			wait = maxIdle.Seconds()
+		}
+		time.Sleep(time.Duration(wait / speed * float64(time.Second)))
+
+		if kind == "o" {
+			if _, err := io.WriteString(out, data); err != nil {
+				return err
+			}
+		}
+	}
+	return p.sc.Err()
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/ssh"
+
+	"terminull-ssh/record"
+)
+
+// startRecording starts recording sess to cfg.RecordDir, pruning old
+// recordings. It returns nil if recording is off or could not start.
+func startRecording(sess ssh.Session, cfg Config, node, width, height int, logger *slog.Logger) *record.Recorder {
+	if cfg.RecordDir == "" {
+		return nil
+	}
+	logger = sessionLogger(sess, logger)
+
+	pty, _, _ := sess.Pty()
+	id := sess.Context().SessionID()
+	if len(id) > 12 {
+		id = id[:12]
+	}
+	name := fmt.Sprintf("%s-node%d-%s", time.Now().UTC().Format("20060102-150405"), node, id)
+	rec, err := record.Start(cfg.RecordDir, name, width, height, pty.Term, fmt.Sprintf("terminull node %d", node))
+	if err != nil {
+		logger.Error("could not start recording", "err", err)
+		return nil
+	}
+	logger.Info("recording session", "file", rec.Path())
+
+	if n, err := record.Prune(cfg.RecordDir, cfg.RecordKeep); err != nil {
+		logger.Warn("could not prune recordings", "err", err)
+	} else if n > 0 {
+		logger.Info("pruned old recordings", "removed", n)
+	}
+
+	go func() {
+		<-sess.Context().Done()
+		if err := rec.Close(); err != nil {
+			logger.Warn("recording incomplete", "file", rec.Path(), "err", err)
+		}
+	}()
+	return rec
+}
+
+// recordOptions tees the program's output into rec and records resizes.
+func recordOptions(sess ssh.Session, rec *record.Recorder) []tea.ProgramOption {
+	return []tea.ProgramOption{
+		tea.WithOutput(rec.Tee(sessionOutput(sess))),
+		tea.WithFilter(func(_ tea.Model, msg tea.Msg) tea.Msg {
+			if ws, ok := msg.(tea.WindowSizeMsg); ok {
+				rec.Resize(ws.Width, ws.Height)
+			}
+			return msg
+		}),
+	}
+}
+
+// sessionOutput returns where bubbletea.MakeOptions sends a program's
+// output: the allocated PTY if there is one, else the session.
+func sessionOutput(sess ssh.Session) io.Writer {
+	if pty, _, ok := sess.Pty(); ok && !sess.EmulatedPty() && pty.Slave != nil {
+		return pty.Slave
+	}
+	return sess
+}
+
+// playRecording replays a recording in the local terminal:
+// `terminull-ssh play <file> [speed]`.
+func playRecording(args []string) error {
+	if len(args) < 1 || len(args) > 2 {
+		return fmt.Errorf("usage: terminull-ssh play <file%s> [speed]", record.Ext)
+	}
+	speed := 1.0
+	if len(args) == 2 {
+		s, err := strconv.ParseFloat(args[1], 64)
+		if err != nil || s <= 0 {
+			return fmt.Errorf("speed %q: want a positive number", args[1])
+		}
+		speed = s
+	}
+
+	f, err := os.Open(args[0])
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	p, err := record.NewPlayer(f)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "%s: recorded at %dx%d on %s\n", args[0], p.Header.Width, p.Header.Height,
+		time.Unix(p.Header.Timestamp, 0).Format("2006-01-02 15:04"))
+	time.Sleep(time.Second)
+	return p.Play(os.Stdout, speed, 2*time.Second)
+}
@@ -0,0 +1,150 @@
+package render
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Budget limits the time rendering a document may take and the size of
+// what it may produce, so pathological markdown, such as deeply nested
+// lists or an enormous table, cannot stall a session. Zero means no limit.
+type Budget struct {
+	Timeout time.Duration
+	MaxSize int // bytes of rendered output
+
+	// Overruns remembers documents found over the budget, so they are not
+	// rendered again; nil remembers nothing.
+	Overruns *Overruns
+}
+
+var (
+	// ErrOverBudget is returned, wrapped, for a rendering over its budget.
+	ErrOverBudget = errors.New("over the render budget")
+
+	// ErrBusy is returned for a rendering not started because too many
+	// others over time are still running.
+	ErrBusy = errors.New("too many renderings over time still running")
+)
+
+// maxOverdue caps the renderings left running after their time ran out.
+// Past it, renderings with a timeout are not started until some finish,
+// so callers opening slow documents cannot pile up goroutines.
+const maxOverdue = 4
+
+// overdue counts the renderings left running after their time ran out.
+var overdue atomic.Int32
+
+// Run calls render for the document k within the budget. A rendering
+// that runs out of time cannot be interrupted, so it carries on in the
+// background and its result is thrown away. Documents over budget are
+// remembered in b.Overruns and fail straight away after that, rather than
+// start another rendering that will be thrown away too.
+func (b Budget) Run(k Key, render func() (string, error)) (string, error) {
+	if err := b.Overruns.Get(k); err != nil {
+		return "", err
+	}
+	type result struct {
+		text string
+		err  error
+	}
+	var r result
+	if b.Timeout <= 0 {
+		r.text, r.err = render()
+	} else {
+		if overdue.Load() >= maxOverdue {
+			return "", ErrBusy
+		}
+		const (
+			running = iota
+			finished
+			abandoned
+		)
+		var state atomic.Int32
+		done := make(chan result, 1)
+		go func() {
+			text, err := render()
+			done <- result{text, err}
+			if !state.CompareAndSwap(running, finished) {
+				overdue.Add(-1)
+			}
+		}()
+		timer := time.NewTimer(b.Timeout)
+		defer timer.Stop()
+		select {
+		case r = <-done:
+		case <-timer.C:
+			if !state.CompareAndSwap(running, abandoned) {
+				r = <-done // finished just now
+				break
+			}
+			overdue.Add(1)
+			return "", b.Overruns.add(k, fmt.Errorf("%w: took longer than %s", ErrOverBudget, b.Timeout))
+		}
+	}
+	if r.err != nil {
+		return "", r.err
+	}
+	if b.MaxSize > 0 && len(r.text) > b.MaxSize {
+		return "", b.Overruns.add(k, fmt.Errorf("%w: %d bytes rendered, limit %d", ErrOverBudget, len(r.text), b.MaxSize))
+	}
+	return r.text, nil
+}
+
+// maxOverruns caps the documents an Overruns remembers.
+const maxOverruns = 1024
+
+// Overruns remembers documents found over a render budget. It is safe
+// for concurrent use, and a nil Overruns remembers nothing.
+type Overruns struct {
+	mu   sync.Mutex
+	errs map[Key]error
+}
+
+// NewOverruns returns an empty Overruns.
+func NewOverruns() *Overruns {
+	return &Overruns{errs: make(map[Key]error)}
+}
+
+// Get returns why the document k was over budget, or nil if it was not
+// found to be.
+func (o *Overruns) Get(k Key) error {
+	if o == nil {
+		return nil
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.errs[k]
+}
+
+// add remembers that the document k was over budget, returning err.
+func (o *Overruns) add(k Key, err error) error {
+	if o == nil {
+		return err
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if len(o.errs) >= maxOverruns {
+		for old := range o.errs {
+			delete(o.errs, old)
+			break
+		}
+	}
+	o.errs[k] = err
+	return err
+}
+
+// Plain returns md as it is, wrapped to width, for showing when it cannot
+// be rendered.
+func Plain(md string, width int) string {
+	md = strings.ReplaceAll(md, "\t", "    ")
+	if width < 1 {
+		return md
+	}
+	return ansi.Wrap(md, width, "")
+}
@@ -0,0 +1,133 @@
+// Package render caches rendered markdown for every session to share, so
+// a popular article is rendered once per terminal width rather than once
+// per caller.
+package render
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+)
+
+// Key identifies one rendering of a document.
+type Key struct {
+	Slug  string // what was rendered, e.g. "vol1/intro"
+	Hash  string // Hash of the markdown, so edited content is rendered afresh
+	Width int    // wrap width
+	Theme string // style it was rendered in
+}
+
+// Hash returns a short digest of a document for its Key.
+func Hash(md string) string {
+	sum := sha256.Sum256([]byte(md))
+	return hex.EncodeToString(sum[:8])
+}
+
+// Cache is a least-recently-used cache of rendered documents. It is safe
+// for concurrent use, and sessions asking for a rendering already under
+// way wait for it rather than render it again. A nil or zero-size Cache
+// caches nothing.
+type Cache struct {
+	size int
+
+	mu      sync.Mutex
+	order   *list.List // of *entry, most recently used first
+	items   map[Key]*list.Element
+	pending map[Key]*call
+}
+
+type entry struct {
+	key  Key
+	text string
+}
+
+// call is a rendering under way.
+type call struct {
+	done chan struct{}
+	text string
+	err  error
+}
+
+// NewCache returns a cache holding up to size renderings.
+func NewCache(size int) *Cache {
+	return &Cache{
+		size:    size,
+		order:   list.New(),
+		items:   make(map[Key]*list.Element),
+		pending: make(map[Key]*call),
+	}
+}
+
+// Get returns the cached rendering for k, if there is one.
+func (c *Cache) Get(k Key) (string, bool) {
+	if c == nil || c.size <= 0 {
+		return "", false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.items[k]
+	if !ok {
+		return "", false
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*entry).text, true
+}
+
+// Render returns the rendering for k, calling render to make it if it is
+// not cached or already being made. Failed renderings are not cached.
+func (c *Cache) Render(k Key, render func() (string, error)) (string, error) {
+	if c == nil || c.size <= 0 {
+		return render()
+	}
+	if text, ok := c.Get(k); ok {
+		return text, nil
+	}
+
+	c.mu.Lock()
+	if cl, ok := c.pending[k]; ok {
+		c.mu.Unlock()
+		<-cl.done
+		return cl.text, cl.err
+	}
+	cl := &call{done: make(chan struct{})}
+	c.pending[k] = cl
+	c.mu.Unlock()
+
+	cl.text, cl.err = render()
+
+	c.mu.Lock()
+	delete(c.pending, k)
+	if cl.err == nil {
+		c.put(k, cl.text)
+	}
+	c.mu.Unlock()
+	close(cl.done)
+	return cl.text, cl.err
+}
+
+// put stores a rendering, evicting the least recently used if the cache
+// is full. Caller must hold c.mu.
+func (c *Cache) put(k Key, text string) {
+	if el, ok := c.items[k]; ok {
+		el.Value.(*entry).text = text
+		c.order.MoveToFront(el)
+		return
+	}
+	c.items[k] = c.order.PushFront(&entry{k, text})
+	for c.order.Len() > c.size {
+		el := c.order.Back()
+		c.order.Remove(el)
+		delete(c.items, el.Value.(*entry).key)
+	}
+}
+
+// Len returns the number of renderings cached.
+func (c *Cache) Len() int {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
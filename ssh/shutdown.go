@@ -0,0 +1,49 @@
+package main
+
+import (
+	"log/slog"
+	"net"
+	"os"
+	"time"
+
+	"terminull-ssh/nodes"
+	"terminull-ssh/ui/types"
+)
+
+// drain stops taking new calls, shows every session a countdown and waits
+// up to d for callers to leave. Sessions already connected but not yet on
+// a node, such as callers waiting in line, get none and are told the
+// server is going down. It reports whether a second signal on sig asked
+// to skip the rest of the wait.
+func drain(lns []net.Listener, reg *nodes.Registry, d time.Duration, sig <-chan os.Signal, logger *slog.Logger) (forced bool) {
+	for _, ln := range lns {
+		ln.Close()
+	}
+	reg.Drain(types.ShutdownMsg{At: time.Now().Add(d)})
+
+	n := reg.Count()
+	if d <= 0 || n == 0 {
+		return false
+	}
+
+	logger.Info("draining sessions (signal again to exit now)", "sessions", n, "for", d)
+
+	deadline := time.NewTimer(d)
+	defer deadline.Stop()
+	poll := time.NewTicker(time.Second)
+	defer poll.Stop()
+
+	for reg.Count() > 0 {
+		select {
+		case <-sig:
+			logger.Warn("second signal, exiting now")
+			return true
+		case <-deadline.C:
+			logger.Warn("drain time is up, hanging up", "sessions", reg.Count())
+			return false
+		case <-poll.C:
+		}
+	}
+	logger.Info("all sessions have left")
+	return false
+}
@@ -0,0 +1,353 @@
+package ui
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"terminull-ssh/nodes"
+	"terminull-ssh/ui/components"
+	"terminull-ssh/ui/layout"
+	"terminull-ssh/ui/theme"
+	"terminull-ssh/ui/types"
+)
+
+// adminTickMsg refreshes the console once a second.
+type adminTickMsg struct{}
+
+// AdminModel is the sysop console reached with `ssh bbs -t admin`. It is
+// a standalone program rather than a screen: it has no node of its own
+// and does not appear among the callers.
+type AdminModel struct {
+	svc     Services
+	nodes   *nodes.Registry
+	started time.Time
+	width   int
+	height  int
+	cursor  int
+
+	confirm string // "kick" or "ban" while waiting for y/n on the selected node
+	prompt  string // "broadcast" or "unban" while typing into input
+	input   textinput.Model
+	status  string
+}
+
+// NewAdmin creates the sysop console for a server that started at started.
+func NewAdmin(svc Services, reg *nodes.Registry, started time.Time, width, height int) *AdminModel {
+	ti := textinput.New()
+	ti.CharLimit = 200
+	ti.TextStyle = lipgloss.NewStyle().Foreground(theme.Text)
+	ti.PromptStyle = lipgloss.NewStyle().Foreground(theme.Gold)
+
+	return &AdminModel{
+		svc:     svc,
+		nodes:   reg,
+		started: started,
+		width:   width,
+		height:  height,
+		input:   ti,
+	}
+}
+
+func (m *AdminModel) Init() tea.Cmd {
+	return adminTick()
+}
+
+func adminTick() tea.Cmd {
+	return tea.Tick(time.Second, func(time.Time) tea.Msg { return adminTickMsg{} })
+}
+
+func (m *AdminModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = layout.Fit(msg.Width, msg.Height)
+		return m, nil
+
+	case adminTickMsg:
+		if n := len(m.nodes.Active()); m.cursor >= n {
+			m.cursor = max(n-1, 0)
+		}
+		return m, adminTick()
+
+	case tea.KeyMsg:
+		if msg.String() == "ctrl+c" {
+			return m, tea.Quit
+		}
+		if m.prompt != "" {
+			return m, m.updatePrompt(msg)
+		}
+		if m.confirm != "" {
+			action := m.confirm
+			m.confirm = ""
+			switch {
+			case msg.String() != "y":
+				m.status = "Cancelled."
+			case action == "ban":
+				m.banSelected()
+			default:
+				m.kickSelected()
+			}
+			return m, nil
+		}
+
+		switch msg.String() {
+		case "j", "down":
+			if m.cursor < len(m.nodes.Active())-1 {
+				m.cursor++
+			}
+		case "k", "up":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case "x":
+			if n := m.selected(); n != nil {
+				m.confirm = "kick"
+				m.status = fmt.Sprintf("Kick node %d (%s)? y/n", n.Number, n.Handle)
+			}
+		case "B":
+			if n := m.selected(); n != nil && n.IP != "" && m.svc.Access != nil {
+				m.confirm = "ban"
+				m.status = fmt.Sprintf("Ban %s and kick node %d (%s)? y/n", n.IP, n.Number, n.Handle)
+			}
+		case "b":
+			return m, m.openPrompt("broadcast", "message to all callers...")
+		case "u":
+			if m.svc.Access != nil {
+				return m, m.openPrompt("unban", "address to unban")
+			}
+		case "r":
+			s := m.svc.Content.Reload()
+			m.status = fmt.Sprintf("Content reloaded: %d articles, %d warnings.", len(s.Articles), len(s.Warnings))
+		case "q", "esc":
+			return m, tea.Quit
+		}
+	}
+
+	return m, nil
+}
+
+// openPrompt starts reading a line of input for kind.
+func (m *AdminModel) openPrompt(kind, placeholder string) tea.Cmd {
+	m.prompt = kind
+	m.status = ""
+	m.input.Prompt = kind + "> "
+	m.input.Placeholder = placeholder
+	m.input.Reset()
+	return m.input.Focus()
+}
+
+// updatePrompt handles keys while a prompt is open.
+func (m *AdminModel) updatePrompt(msg tea.KeyMsg) tea.Cmd {
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.prompt = ""
+		m.input.Blur()
+		return nil
+	case tea.KeyEnter:
+		kind := m.prompt
+		m.prompt = ""
+		m.input.Blur()
+		text := strings.TrimSpace(m.input.Value())
+		if text == "" {
+			return nil
+		}
+		switch kind {
+		case "broadcast":
+			n := m.nodes.Broadcast(types.BroadcastMsg{Text: text})
+			m.status = fmt.Sprintf("Broadcast sent to %d session(s).", n)
+		case "unban":
+			ok, err := m.svc.Access.Unban(text)
+			switch {
+			case err != nil:
+				m.status = err.Error()
+			case !ok:
+				m.status = text + " is not banned."
+			default:
+				m.status = "Unbanned " + text + "."
+			}
+		}
+		return nil
+	}
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return cmd
+}
+
+// selected returns the node under the cursor, if any.
+func (m *AdminModel) selected() *nodes.Node {
+	active := m.nodes.Active()
+	if m.cursor < 0 || m.cursor >= len(active) {
+		return nil
+	}
+	return active[m.cursor]
+}
+
+func (m *AdminModel) kickSelected() {
+	n := m.selected()
+	if n == nil {
+		m.status = "That caller already left."
+		return
+	}
+	if !m.nodes.Kick(n.Number) {
+		m.status = fmt.Sprintf("Node %d cannot be reached.", n.Number)
+		return
+	}
+	m.status = fmt.Sprintf("Kicked node %d (%s).", n.Number, n.Handle)
+}
+
+// banSelected bans the selected caller's address and kicks them.
+func (m *AdminModel) banSelected() {
+	n := m.selected()
+	if n == nil {
+		m.status = "That caller already left."
+		return
+	}
+	if err := m.svc.Access.Ban(n.IP, 0, "sysop"); err != nil {
+		m.status = "could not ban: " + err.Error()
+		return
+	}
+	m.nodes.Kick(n.Number)
+	m.status = fmt.Sprintf("Banned %s and kicked node %d (%s).", n.IP, n.Number, n.Handle)
+}
+
+func (m *AdminModel) View() string {
+	w := m.width
+	if w > theme.MaxWidth {
+		w = theme.MaxWidth
+	}
+
+	var b strings.Builder
+
+	titleStyle := lipgloss.NewStyle().Foreground(theme.Gold).Bold(true)
+	labelStyle := lipgloss.NewStyle().Foreground(theme.Muted)
+	valueStyle := lipgloss.NewStyle().Foreground(theme.Text)
+	headStyle := lipgloss.NewStyle().Foreground(theme.Cyan).Bold(true)
+
+	b.WriteString(titleStyle.Render("SYSOP CONSOLE"))
+	b.WriteString("\n")
+	b.WriteString(components.RenderDivider(w))
+	b.WriteString("\n")
+
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	b.WriteString(labelStyle.Render("  uptime ") + valueStyle.Render(components.FormatDuration(time.Since(m.started))) +
+		labelStyle.Render("   heap ") + valueStyle.Render(formatBytes(mem.HeapAlloc)) +
+		labelStyle.Render("   sys ") + valueStyle.Render(formatBytes(mem.Sys)) +
+		labelStyle.Render("   goroutines ") + valueStyle.Render(fmt.Sprint(runtime.NumGoroutine())))
+	b.WriteString("\n")
+
+	store := m.svc.Content.Store()
+	b.WriteString(labelStyle.Render("  content ") + valueStyle.Render(fmt.Sprintf("%d volumes, %d articles, %d pages, %d polls, %d challenges",
+		len(store.Volumes), len(store.Articles), len(store.Pages), len(store.Polls), len(store.Challenges()))))
+	b.WriteString("\n")
+	b.WriteString(labelStyle.Render("  loaded  ") + valueStyle.Render(store.LoadedAt.Format("2006-01-02 15:04:05")))
+	b.WriteString("\n\n")
+
+	active := m.nodes.Active()
+	b.WriteString(headStyle.Render(fmt.Sprintf("SESSIONS (%d)", len(active))))
+	b.WriteString("\n")
+	if len(active) == 0 {
+		b.WriteString(labelStyle.Render("  Nobody is online."))
+		b.WriteString("\n")
+	} else {
+		b.WriteString(labelStyle.Render(fmt.Sprintf("  %-6s%-20s%-8s%-10s%s", "NODE", "HANDLE", "TYPE", "SINCE", "ONLINE")))
+		b.WriteString("\n")
+		for i, n := range active {
+			kind := "guest"
+			if n.Member {
+				kind = "member"
+			}
+			row := fmt.Sprintf("%-6d%-20s%-8s%-10s%s", n.Number, truncateRunes(n.Handle, 19), kind,
+				n.Connected.Format("15:04:05"), components.FormatDuration(time.Since(n.Connected)))
+			if i == m.cursor {
+				b.WriteString(lipgloss.NewStyle().Foreground(theme.Green).Render("▸ ") +
+					lipgloss.NewStyle().Foreground(theme.GreenBright).Bold(true).Render(row))
+			} else {
+				b.WriteString("  " + valueStyle.Render(row))
+			}
+			b.WriteString("\n")
+		}
+	}
+	b.WriteString("\n")
+
+	if m.svc.Access != nil {
+		bans := m.svc.Access.Bans()
+		b.WriteString(headStyle.Render(fmt.Sprintf("BANS (%d)", len(bans))))
+		b.WriteString("\n")
+		const maxBans = 5
+		for i, ban := range bans {
+			if i == maxBans {
+				b.WriteString(labelStyle.Render(fmt.Sprintf("  … and %d more", len(bans)-maxBans)))
+				b.WriteString("\n")
+				break
+			}
+			b.WriteString(valueStyle.Render(fmt.Sprintf("  %-40s", ban.IP)) +
+				labelStyle.Render(fmt.Sprintf("%s left  %s", components.FormatDuration(time.Until(ban.Until)), ban.Reason)))
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString(headStyle.Render(fmt.Sprintf("LOADER WARNINGS (%d)", len(store.Warnings))))
+	b.WriteString("\n")
+	warnStyle := lipgloss.NewStyle().Foreground(theme.Secondary)
+	const maxWarnings = 8
+	for i, warn := range store.Warnings {
+		if i == maxWarnings {
+			b.WriteString(labelStyle.Render(fmt.Sprintf("  … and %d more", len(store.Warnings)-maxWarnings)))
+			b.WriteString("\n")
+			break
+		}
+		b.WriteString(warnStyle.Render("  " + truncateRunes(warn, w-2)))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	if m.prompt != "" {
+		b.WriteString(m.input.View())
+		b.WriteString("\n")
+	} else if m.status != "" {
+		b.WriteString("  " + lipgloss.NewStyle().Foreground(theme.Gold).Render(m.status))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	hint := "  x kick  |  B ban  |  u unban  |  b broadcast  |  r reload  |  q quit"
+	if m.prompt != "" {
+		hint = "  Enter to send  |  Esc to cancel"
+	}
+	b.WriteString(labelStyle.Render(hint))
+	b.WriteString("\n")
+
+	return b.String()
+}
+
+// formatBytes renders n as a short human-readable size.
+func formatBytes(n uint64) string {
+	switch {
+	case n >= 1<<30:
+		return fmt.Sprintf("%.1fG", float64(n)/(1<<30))
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1fM", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1fK", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%dB", n)
+}
+
+// truncateRunes cuts s to at most n runes.
+func truncateRunes(s string, n int) string {
+	r := []rune(s)
+	if n <= 0 {
+		return ""
+	}
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
@@ -1,75 +1,149 @@
 package ui
 
 import (
+	"fmt"
+	"io"
+	"time"
+
+	"terminull-ssh/access"
+	"terminull-ssh/callers"
 	"terminull-ssh/content"
+	"terminull-ssh/ctf"
+	"terminull-ssh/door"
+	"terminull-ssh/metrics"
+	"terminull-ssh/nodes"
+	"terminull-ssh/oneliners"
+	"terminull-ssh/render"
 	"terminull-ssh/ui/components"
+	"terminull-ssh/ui/layout"
 	"terminull-ssh/ui/screens"
+	"terminull-ssh/ui/theme"
 	"terminull-ssh/ui/types"
+	"terminull-ssh/votes"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 )
 
 const maxStackDepth = 20
 
+// Services holds the server-wide state shared read-only by every session.
+type Services struct {
+	Content  *content.Library
+	Doors    *door.Registry
+	Wall     *oneliners.Wall
+	Callers  *callers.Log
+	Votes    *votes.Booth
+	Scores   *ctf.Scoreboard
+	Access   *access.Guard
+	SiteURL  string
+	Renders  *render.Cache // rendered articles, shared by every session
+	Budget   render.Budget // limits on rendering any one document
+	Timeouts Timeouts
+}
+
+// Session identifies the caller behind a single SSH connection.
+type Session struct {
+	Username string
+	Node     *nodes.Node
+	KeyID    string // SHA256 fingerprint of the caller's public key; empty for guests
+
+	Recording bool // the session is being recorded; shown in the status bar
+
+	// Terminal is the caller's terminal, for sequences sent outside the
+	// frames Bubble Tea draws, such as clipboard writes; nil drops them.
+	Terminal io.Writer
+}
+
 // AppModel is the root Bubble Tea model managing a screen stack.
 type AppModel struct {
-	store    *content.Store
+	content  *content.Library
+	doors    *door.Registry
+	calls    *callers.Log
+	votes    *votes.Booth
+	scores   *ctf.Scoreboard
 	siteURL  string
+	renders  *render.Cache
+	budget   render.Budget
 	username string
+	keyID    string
+	node     *nodes.Node
 	width    int
 	height   int
 	stack    []types.Screen
+	banner   string    // latest sysop broadcast, until dismissed
+	downAt   time.Time // when the server goes down, once it is draining
+	timeouts Timeouts
+	started  time.Time
+	lastKey  time.Time
+	goodbye  string // set once the session is ending, shown until it does
+	rec      bool
+	terminal io.Writer
 }
 
 // NewApp creates the root application model.
-func NewApp(store *content.Store, width, height int, username, siteURL string) *AppModel {
-	if width < 40 {
+func NewApp(svc Services, sess Session, width, height int) *AppModel {
+	if width <= 0 {
 		width = 80
 	}
-	if height < 10 {
+	if height <= 0 {
 		height = 24
 	}
-	if username == "" {
-		username = "guest"
+	width, height = layout.Fit(width, height)
+	if sess.Username == "" {
+		sess.Username = "guest"
 	}
 
 	app := &AppModel{
-		store:    store,
-		siteURL:  siteURL,
-		username: username,
+		content:  svc.Content,
+		doors:    svc.Doors,
+		calls:    svc.Callers,
+		votes:    svc.Votes,
+		scores:   svc.Scores,
+		siteURL:  svc.SiteURL,
+		renders:  svc.Renders,
+		budget:   svc.Budget,
+		username: sess.Username,
+		keyID:    sess.KeyID,
+		node:     sess.Node,
 		width:    width,
 		height:   height,
+		timeouts: svc.Timeouts,
+		started:  time.Now(),
+		lastKey:  time.Now(),
+		rec:      sess.Recording,
+		terminal: sess.Terminal,
 	}
 
 	// Start with home screen
-	home := screens.NewHomeScreen(store, width, height, username, siteURL)
+	home := screens.NewHomeScreen(svc.Content.Store(), svc.Doors, svc.Wall, svc.Callers, width, height, sess.Username, sess.KeyID, svc.SiteURL)
 	app.stack = []types.Screen{home}
 
 	return app
 }
 
 func (a *AppModel) Init() tea.Cmd {
+	var cmds []tea.Cmd
 	if len(a.stack) > 0 {
-		return a.stack[len(a.stack)-1].Init()
+		cmds = append(cmds, a.stack[len(a.stack)-1].Init())
 	}
-	return nil
+	if a.timeouts.Idle > 0 || a.timeouts.Max > 0 {
+		cmds = append(cmds, timeoutTick())
+	}
+	return tea.Batch(cmds...)
 }
 
 func (a *AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
+		msg.Width, msg.Height = layout.Fit(msg.Width, msg.Height)
 		a.width = msg.Width
 		a.height = msg.Height
-		// Propagate to all screens
-		var cmds []tea.Cmd
-		for i, s := range a.stack {
-			updated, cmd := s.Update(msg)
-			a.stack[i] = updated.(types.Screen)
-			if cmd != nil {
-				cmds = append(cmds, cmd)
-			}
-		}
-		return a, tea.Batch(cmds...)
+		return a, a.broadcast(msg)
+
+	case types.BackgroundMsg:
+		return a, a.broadcast(msg)
 
 	case types.NavigateMsg:
 		return a.navigate(msg)
@@ -77,6 +151,28 @@ func (a *AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case types.ReplaceMsg:
 		return a.replace(msg)
 
+	case types.BroadcastMsg:
+		a.banner = msg.Text
+		return a, nil
+
+	case types.ClipboardMsg:
+		return a, a.setClipboard(msg.Text)
+
+	case types.ShutdownMsg:
+		a.downAt = msg.At
+		return a, shutdownTick()
+
+	case shutdownTickMsg:
+		// Keep the countdown moving until the server hangs up.
+		return a, shutdownTick()
+
+	case timeoutTickMsg:
+		return a, a.checkTimeouts(msg)
+
+	case types.KickMsg:
+		// Show the notice for a moment before dropping the connection.
+		return a, a.hangUp("The sysop has ended your session. Goodbye.")
+
 	case types.BackMsg:
 		if len(a.stack) > 1 {
 			a.stack = a.stack[:len(a.stack)-1]
@@ -85,6 +181,21 @@ func (a *AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return a, tea.Quit
 
 	case tea.KeyMsg:
+		if a.goodbye != "" {
+			return a, nil
+		}
+		a.lastKey = time.Now()
+		// Keys do nothing the caller could see until the terminal is big
+		// enough to draw in.
+		if layout.TooSmall(a.width, a.height) && msg.String() != "ctrl+c" {
+			return a, nil
+		}
+		// Esc dismisses a broadcast before it reaches the screen, so the
+		// screen underneath keeps its state.
+		if a.banner != "" && msg.Type == tea.KeyEsc {
+			a.banner = ""
+			return a, nil
+		}
 		switch msg.String() {
 		case "ctrl+c":
 			return a, tea.Quit
@@ -110,35 +221,127 @@ func (a *AppModel) View() string {
 	active := a.stack[len(a.stack)-1]
 	page, vol := active.StatusInfo()
 
+	if a.goodbye != "" {
+		return fit(components.RenderNotice("DISCONNECTED", a.goodbye, a.width), a.width, a.height)
+	}
+	if layout.TooSmall(a.width, a.height) {
+		return components.RenderTooSmall(a.width, a.height, layout.MinWidth, layout.MinHeight)
+	}
+
 	screenContent := active.View()
-	statusBar := components.RenderStatusBar(page, vol, a.width)
+	status := components.Status{
+		Page:   page,
+		Volume: vol,
+		Alert:  a.timeoutAlert(),
+		Rec:    a.rec,
+	}
+	if s, ok := active.(types.Sectioned); ok {
+		status.Section = s.Section()
+	}
+	if f, ok := active.(types.Finder); ok {
+		status.Find = f.FindStatus()
+	}
+	statusBar := components.RenderStatusBar(status, a.width)
 
-	return screenContent + "\n" + statusBar
+	var banner string
+	switch {
+	case !a.downAt.IsZero():
+		left := time.Until(a.downAt).Round(time.Second)
+		if left < 0 {
+			left = 0
+		}
+		banner = components.RenderBanner(fmt.Sprintf("SYSTEM GOING DOWN IN %s -- please finish up", left), "", a.width)
+	case a.banner != "":
+		banner = components.RenderBanner("SYSOP: "+a.banner, "esc dismiss", a.width)
+	}
+	// A screen taller or wider than the terminal is cut to fit rather
+	// than scroll the status bar away or wrap.
+	if banner != "" {
+		return fit(screenContent, a.width, a.height-2) + "\n" + banner + "\n" + statusBar
+	}
+	return fit(screenContent, a.width, a.height-1) + "\n" + statusBar
+}
+
+// setClipboard returns a command asking the caller's terminal to put text
+// on the clipboard (OSC 52). It is written straight to the terminal, once,
+// since a frame carrying it could be replaced by the next before it is
+// drawn, and kept out of the session recording.
+func (a *AppModel) setClipboard(text string) tea.Cmd {
+	w := a.terminal
+	if w == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		_, _ = io.WriteString(w, ansi.SetSystemClipboard(text))
+		return nil
+	}
+}
+
+// broadcast sends msg to every screen on the stack, not just the one on
+// top.
+func (a *AppModel) broadcast(msg tea.Msg) tea.Cmd {
+	var cmds []tea.Cmd
+	for i, s := range a.stack {
+		updated, cmd := s.Update(msg)
+		a.stack[i] = updated.(types.Screen)
+		if cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+// shutdownTickMsg redraws the shutdown countdown.
+type shutdownTickMsg struct{}
+
+func shutdownTick() tea.Cmd {
+	return tea.Tick(time.Second, func(time.Time) tea.Msg { return shutdownTickMsg{} })
+}
+
+// fit cuts s to at most width columns and height lines.
+func fit(s string, width, height int) string {
+	return lipgloss.NewStyle().MaxWidth(max(width, 0)).MaxHeight(max(height, 0)).Render(s)
 }
 
 func (a *AppModel) navigate(msg types.NavigateMsg) (*AppModel, tea.Cmd) {
 	var screen types.Screen
 
 	contentWidth := a.width
-	if contentWidth > 78 {
-		contentWidth = 78
+	if contentWidth > theme.MaxWidth {
+		contentWidth = theme.MaxWidth
 	}
 	contentHeight := a.height - 1
+	store := a.content.Store()
 
 	switch msg.Screen {
+	case "home":
+		// Home is always at the bottom of the stack.
+		a.stack = a.stack[:1]
+		return a, nil
+	// These lay themselves out for the whole terminal; see package layout.
 	case "volume":
-		screen = screens.NewVolumeScreen(a.store, msg.Volume, contentWidth, contentHeight)
+		screen = screens.NewVolumeScreen(store, msg.Volume, a.width, contentHeight)
 	case "article":
-		screen = screens.NewArticleScreen(a.store, msg.Volume, msg.Article, contentWidth, contentHeight, a.siteURL)
+		screen = screens.NewArticleScreen(store, a.scores, msg.Volume, msg.Article, a.width, contentHeight, a.username, a.keyID, a.siteURL, a.renders, a.budget)
 	case "page":
-		screen = screens.NewPageScreen(a.store, msg.PageSlug, contentWidth, contentHeight)
+		screen = screens.NewPageScreen(store, msg.PageSlug, contentWidth, contentHeight, a.budget)
 	case "help":
 		screen = screens.NewHelpScreen(contentWidth, contentHeight)
 	case "search":
-		screen = screens.NewSearchScreen(a.store, contentWidth, contentHeight, msg.Query)
+		screen = screens.NewSearchScreen(store, contentWidth, contentHeight, msg.Query)
+	case "doors":
+		// Doors get the full terminal, so pass its real size.
+		screen = screens.NewDoorsScreen(a.doors, a.username, a.nodeNumber(), a.width, a.height)
+	case "callers":
+		screen = screens.NewCallersScreen(a.calls, a.node, a.keyID, contentWidth, contentHeight)
+	case "polls":
+		screen = screens.NewPollsScreen(store, a.votes, a.keyID, contentWidth, contentHeight)
+	case "scoreboard":
+		screen = screens.NewScoreboardScreen(store, a.scores, contentWidth, contentHeight)
 	default:
 		return a, nil
 	}
+	metrics.Navigations.WithLabelValues(msg.Screen).Inc()
 
 	if len(a.stack) >= maxStackDepth {
 		// At max depth — replace top screen instead of pushing
@@ -149,16 +352,22 @@ func (a *AppModel) navigate(msg types.NavigateMsg) (*AppModel, tea.Cmd) {
 	return a, screen.Init()
 }
 
-func (a *AppModel) replace(msg types.ReplaceMsg) (*AppModel, tea.Cmd) {
-	contentWidth := a.width
-	if contentWidth > 78 {
-		contentWidth = 78
+// nodeNumber returns the caller's node, or 0 when running without a registry.
+func (a *AppModel) nodeNumber() int {
+	if a.node == nil {
+		return 0
 	}
+	return a.node.Number
+}
+
+func (a *AppModel) replace(msg types.ReplaceMsg) (*AppModel, tea.Cmd) {
 	contentHeight := a.height - 1
+	store := a.content.Store()
 
 	switch msg.Screen {
 	case "article":
-		screen := screens.NewArticleScreen(a.store, msg.Volume, msg.Article, contentWidth, contentHeight, a.siteURL)
+		screen := screens.NewArticleScreen(store, a.scores, msg.Volume, msg.Article, a.width, contentHeight, a.username, a.keyID, a.siteURL, a.renders, a.budget)
+		metrics.Navigations.WithLabelValues(msg.Screen).Inc()
 		if len(a.stack) > 0 {
 			a.stack[len(a.stack)-1] = screen
 		}
@@ -0,0 +1,141 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"terminull-ssh/ui/components"
+	"terminull-ssh/ui/layout"
+	"terminull-ssh/ui/theme"
+	"terminull-ssh/ui/types"
+)
+
+// busyHangup is how long a caller who cannot wait in line sees the busy
+// screen before being disconnected.
+const busyHangup = 10 * time.Second
+
+// busyDownHangup is how long a caller sees that the server is going down
+// before being disconnected.
+const busyDownHangup = 3 * time.Second
+
+// busyTickMsg redraws the busy screen's wait timer.
+type busyTickMsg struct{}
+
+// BusyModel is shown instead of the board when every node is taken. When
+// queued, it shows the caller's place in line until a node frees up.
+type BusyModel struct {
+	max      int
+	reserved int
+	member   bool
+	queued   bool
+	position int
+	ready    bool
+	down     bool // the server is going down, so no node will come free
+	since    time.Time
+	width    int
+	height   int
+}
+
+// NewBusy creates the busy screen for a board with max nodes, reserved of
+// them for members.
+func NewBusy(max, reserved int, member, queued bool, position, width, height int) *BusyModel {
+	return &BusyModel{
+		max:      max,
+		reserved: reserved,
+		member:   member,
+		queued:   queued,
+		position: position,
+		since:    time.Now(),
+		width:    width,
+		height:   height,
+	}
+}
+
+func (m *BusyModel) Init() tea.Cmd {
+	return busyTick()
+}
+
+func busyTick() tea.Cmd {
+	return tea.Tick(time.Second, func(time.Time) tea.Msg { return busyTickMsg{} })
+}
+
+func (m *BusyModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = layout.Fit(msg.Width, msg.Height)
+	case types.QueueMsg:
+		m.position = msg.Position
+	case types.NodeReadyMsg:
+		m.ready = true
+		return m, tea.Quit
+	case types.ShutdownMsg:
+		m.down = true
+		return m, tea.Tick(busyDownHangup, func(time.Time) tea.Msg { return tea.QuitMsg{} })
+	case busyTickMsg:
+		if !m.queued && !m.down && time.Since(m.since) >= busyHangup {
+			return m, tea.Quit
+		}
+		return m, busyTick()
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "esc", "ctrl+c":
+			return m, tea.Quit
+		}
+	}
+	return m, nil
+}
+
+// Ready reports whether the caller was handed a node.
+func (m *BusyModel) Ready() bool { return m.ready }
+
+func (m *BusyModel) View() string {
+	w := m.width
+	if w > theme.MaxWidth {
+		w = theme.MaxWidth
+	}
+
+	var b strings.Builder
+
+	b.WriteString("\n")
+	b.WriteString(lipgloss.NewStyle().Foreground(theme.Red).Bold(true).Render("ALL NODES BUSY — try again later"))
+	b.WriteString("\n")
+	b.WriteString(components.RenderDivider(w))
+	b.WriteString("\n\n")
+
+	textStyle := lipgloss.NewStyle().Foreground(theme.Text)
+	mutedStyle := lipgloss.NewStyle().Foreground(theme.Muted)
+
+	if m.reserved > 0 && !m.member {
+		b.WriteString(textStyle.Render(fmt.Sprintf("  All %d guest nodes on terminull are in use right now.", m.max-m.reserved)))
+		b.WriteString("\n")
+		b.WriteString(mutedStyle.Render(fmt.Sprintf("  Another %d are reserved for members. Call with an SSH key to use one.", m.reserved)))
+	} else {
+		b.WriteString(textStyle.Render(fmt.Sprintf("  All %d nodes on terminull are in use right now.", m.max)))
+	}
+	b.WriteString("\n")
+	b.WriteString("\n")
+
+	if m.down {
+		b.WriteString(lipgloss.NewStyle().Foreground(theme.Red).Bold(true).Render("  terminull is going down. Please call back later."))
+	} else if m.queued {
+		pos := "…"
+		if m.position > 0 {
+			pos = fmt.Sprint(m.position)
+		}
+		b.WriteString(lipgloss.NewStyle().Foreground(theme.Gold).Bold(true).Render("  You are number " + pos + " in line."))
+		b.WriteString("\n")
+		b.WriteString(mutedStyle.Render("  Waiting " + components.FormatDuration(time.Since(m.since).Round(time.Second)) +
+			". You'll be connected as soon as a node frees up."))
+		b.WriteString("\n\n")
+		b.WriteString(mutedStyle.Render("  q to hang up"))
+	} else {
+		b.WriteString(mutedStyle.Render("  Hanging up. Please call back in a few minutes."))
+	}
+	b.WriteString("\n")
+
+	return b.String()
+}
@@ -9,7 +9,8 @@ import (
 )
 
 // RenderBoxFrame draws a box-drawing character frame around content.
-// Matches BoxFrame.astro and buildBoxFrame() from ansi-text.ts.
+// Matches BoxFrame.astro and buildBoxFrame() from ansi-text.ts. Lines too
+// long for the frame are wrapped.
 func RenderBoxFrame(title string, lines []string, width int) string {
 	if width < 10 {
 		width = 10
@@ -23,8 +24,8 @@ func RenderBoxFrame(title string, lines []string, width int) string {
 	// Top border
 	var top string
 	if title != "" {
-		titleStr := "[ " + title + " ]"
-		remaining := innerWidth - 1 - len(titleStr) // -1 for ─ after ┌
+		titleStr := "[ " + Truncate(title, innerWidth-5) + " ]"
+		remaining := innerWidth - 1 - lipgloss.Width(titleStr) // -1 for ─ after ┌
 		if remaining < 0 {
 			remaining = 0
 		}
@@ -37,7 +38,16 @@ func RenderBoxFrame(title string, lines []string, width int) string {
 
 	// Content lines
 	var contentLines []string
+	wrap := lipgloss.NewStyle().Width(innerWidth - 1)
+	var wrapped []string
 	for _, line := range lines {
+		if lipgloss.Width(line) > innerWidth-1 {
+			wrapped = append(wrapped, strings.Split(wrap.Render(line), "\n")...)
+			continue
+		}
+		wrapped = append(wrapped, line)
+	}
+	for _, line := range wrapped {
 		// Pad line to inner width (accounting for visible length)
 		visible := lipgloss.Width(line)
 		padding := innerWidth - visible - 1 // -1 for leading space
@@ -1,10 +1,14 @@
 package components
 
 import (
+	"fmt"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
 
+	"terminull-ssh/callers"
+	"terminull-ssh/oneliners"
 	"terminull-ssh/ui/theme"
 )
 
@@ -36,8 +40,8 @@ func RenderFooter(width int) string {
 // RenderMOTD returns the "message of the day" section.
 func RenderMOTD(width int) string {
 	boxWidth := width
-	if boxWidth > 78 {
-		boxWidth = 78
+	if boxWidth > theme.MaxWidth {
+		boxWidth = theme.MaxWidth
 	}
 	lines := []string{
 		"Knowledge wants to be free.",
@@ -46,3 +50,79 @@ func RenderMOTD(width int) string {
 	}
 	return RenderBoxFrame("MOTD", lines, boxWidth)
 }
+
+// RenderOneliners returns the oneliner wall box, newest entry first.
+func RenderOneliners(entries []oneliners.Entry, width int) string {
+	boxWidth := width
+	if boxWidth > theme.MaxWidth {
+		boxWidth = theme.MaxWidth
+	}
+	handleStyle := lipgloss.NewStyle().Foreground(theme.Green)
+	textStyle := lipgloss.NewStyle().Foreground(theme.Text)
+
+	var lines []string
+	for _, e := range entries {
+		handle := e.Handle
+		if r := []rune(handle); len(r) > 12 {
+			handle = string(r[:12])
+		}
+		line := handleStyle.Render(padRight(handle, 12)) + " " + textStyle.Render(e.Text)
+		if lipgloss.Width(line) > boxWidth-3 {
+			line = lipgloss.NewStyle().MaxWidth(boxWidth - 3).Render(line)
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) == 0 {
+		lines = append(lines, "The wall is empty. Be the first to write on it.")
+	}
+	return RenderBoxFrame("ONELINERS", lines, boxWidth)
+}
+
+// RenderLastCallers returns the last callers box, newest call first.
+func RenderLastCallers(calls []callers.Call, width int) string {
+	boxWidth := width
+	if boxWidth > theme.MaxWidth {
+		boxWidth = theme.MaxWidth
+	}
+	handleStyle := lipgloss.NewStyle().Foreground(theme.Green)
+	metaStyle := lipgloss.NewStyle().Foreground(theme.Secondary)
+
+	var lines []string
+	for _, c := range calls {
+		handle := c.Handle
+		if r := []rune(handle); len(r) > 16 {
+			handle = string(r[:16])
+		}
+		kind := "guest"
+		if c.Member {
+			kind = "member"
+		}
+		lines = append(lines, handleStyle.Render(padRight(handle, 16))+" "+
+			metaStyle.Render(fmt.Sprintf("node %-2d  %s  %7s  %s",
+				c.Node, c.Connected.Format("01-02 15:04"), FormatDuration(c.Duration), kind)))
+	}
+	if len(lines) == 0 {
+		lines = append(lines, "No callers yet.")
+	}
+	return RenderBoxFrame(fmt.Sprintf("LAST %d CALLERS", len(calls)), lines, boxWidth)
+}
+
+// FormatDuration renders a session length compactly: 45s, 12m, 1h02m.
+func FormatDuration(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	default:
+		return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+	}
+}
+
+// padRight pads s with spaces to width n.
+func padRight(s string, n int) string {
+	if w := lipgloss.Width(s); w < n {
+		return s + strings.Repeat(" ", n-w)
+	}
+	return s
+}
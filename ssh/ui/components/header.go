@@ -11,12 +11,15 @@ import (
 	"terminull-ssh/ui/theme"
 )
 
-// RenderLogo returns the ASCII logo colored green.
+// RenderLogo returns the ASCII logo colored green, or the wordmark when
+// the logo is wider than width.
 func RenderLogo(width int) string {
 	logoStyle := lipgloss.NewStyle().Foreground(theme.Green)
 	logo := strings.TrimRight(art.Logo, "\n")
+	if lipgloss.Width(logo) > width {
+		return RenderWordmark()
+	}
 
-	// Center logo if terminal is wide enough
 	lines := strings.Split(logo, "\n")
 	var result []string
 	for _, line := range lines {
@@ -25,6 +28,12 @@ func RenderLogo(width int) string {
 	return strings.Join(result, "\n")
 }
 
+// RenderWordmark returns the name on one line, standing in for the logo
+// where there is no room for it.
+func RenderWordmark() string {
+	return lipgloss.NewStyle().Foreground(theme.Green).Bold(true).Render("T E R M I N U L L")
+}
+
 // RenderTagline returns the centered tagline.
 func RenderTagline(latestVolume int, width int) string {
 	tagBase := "h a c k e r   e - z i n e"
@@ -34,6 +43,12 @@ func RenderTagline(latestVolume int, width int) string {
 	} else {
 		tagline = fmt.Sprintf("[ %s ]", tagBase)
 	}
+	if len(tagline) > width {
+		tagline = "[ hacker e-zine ]"
+		if latestVolume > 0 {
+			tagline = fmt.Sprintf("[ hacker e-zine // vol.%d ]", latestVolume)
+		}
+	}
 
 	style := lipgloss.NewStyle().Foreground(theme.Green)
 	pad := (width - len(tagline)) / 2
@@ -51,8 +66,8 @@ func RenderSystemInfo(username string, width int) string {
 	}
 
 	boxWidth := width
-	if boxWidth > 78 {
-		boxWidth = 78
+	if boxWidth > theme.MaxWidth {
+		boxWidth = theme.MaxWidth
 	}
 
 	lines := []string{
@@ -0,0 +1,153 @@
+package components
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+
+	"terminull-ssh/ui/theme"
+)
+
+// Match is a piece of text found on screen: the line it is on and its
+// byte range within that line once escape sequences are removed.
+type Match struct {
+	Line, Start, End int
+}
+
+// FindAll returns every match of re in s, which may contain ANSI escape
+// sequences. Matches do not span lines.
+func FindAll(s string, re *regexp.Regexp) []Match {
+	var out []Match
+	for i, line := range strings.Split(s, "\n") {
+		for _, m := range re.FindAllStringIndex(plainText(line), -1) {
+			if m[0] < m[1] {
+				out = append(out, Match{Line: i, Start: m[0], End: m[1]})
+			}
+		}
+	}
+	return out
+}
+
+// Highlight returns s with the matches marked, the one at index current
+// brighter than the rest. The styling already in s is kept around each
+// match and restored after it.
+func Highlight(s string, matches []Match, current int) string {
+	lines := strings.Split(s, "\n")
+	spans := make(map[int][]span)
+	for i, m := range matches {
+		if m.Line < len(lines) {
+			spans[m.Line] = append(spans[m.Line], span{m.Start, m.End, i == current})
+		}
+	}
+	for n, sp := range spans {
+		lines[n] = highlightLine(lines[n], sp)
+	}
+	return strings.Join(lines, "\n")
+}
+
+type span struct {
+	start, end int
+	current    bool
+}
+
+var (
+	matchStyle   = fmt.Sprintf("\x1b[38;5;%s;48;5;%sm", theme.BgDeep, theme.GoldDim)
+	currentStyle = fmt.Sprintf("\x1b[1;38;5;%s;48;5;%sm", theme.BgDeep, theme.Gold)
+)
+
+// highlightLine marks spans, given in plain-text bytes and in order, in
+// one line. Styles switched on inside a span are held back so they do not
+// override the highlight, then applied after it along with the rest of the
+// style in effect.
+func highlightLine(line string, spans []span) string {
+	var b, style strings.Builder
+	pos, k, in := 0, 0, false
+	for i := 0; i < len(line); {
+		if line[i] == ansi.ESC {
+			seq := line[i : i+escapeLen(line[i:])]
+			i += len(seq)
+			if isSGR(seq) {
+				if seq == "\x1b[m" || seq == "\x1b[0m" {
+					style.Reset()
+				} else {
+					style.WriteString(seq)
+				}
+				if in {
+					continue
+				}
+			}
+			b.WriteString(seq)
+			continue
+		}
+
+		if !in && k < len(spans) && pos == spans[k].start {
+			if spans[k].current {
+				b.WriteString(currentStyle)
+			} else {
+				b.WriteString(matchStyle)
+			}
+			in = true
+		}
+		b.WriteByte(line[i])
+		i++
+		pos++
+		if in && pos == spans[k].end {
+			b.WriteString(ansi.ResetStyle + style.String())
+			in = false
+			k++
+		}
+	}
+	if in {
+		b.WriteString(ansi.ResetStyle)
+	}
+	return b.String()
+}
+
+// plainText returns line without escape sequences.
+func plainText(line string) string {
+	var b strings.Builder
+	for i := 0; i < len(line); {
+		if line[i] == ansi.ESC {
+			i += escapeLen(line[i:])
+			continue
+		}
+		b.WriteByte(line[i])
+		i++
+	}
+	return b.String()
+}
+
+// escapeLen returns the length of the escape sequence s starts with: a
+// CSI sequence, an OSC string, or ESC and one more byte.
+func escapeLen(s string) int {
+	if len(s) < 2 {
+		return len(s)
+	}
+	switch s[1] {
+	case '[':
+		for i := 2; i < len(s); i++ {
+			if s[i] >= 0x40 && s[i] <= 0x7e {
+				return i + 1
+			}
+		}
+	case ']':
+		for i := 2; i < len(s); i++ {
+			if s[i] == ansi.BEL {
+				return i + 1
+			}
+			if s[i] == ansi.ESC && i+1 < len(s) && s[i+1] == '\\' {
+				return i + 2
+			}
+		}
+	default:
+		return 2
+	}
+	return len(s)
+}
+
+// isSGR reports whether seq sets text style.
+func isSGR(seq string) bool {
+	return strings.HasPrefix(seq, "\x1b[") && strings.HasSuffix(seq, "m")
+}
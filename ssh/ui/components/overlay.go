@@ -0,0 +1,26 @@
+package components
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Overlay draws fg over bg with its top-left corner at column x of line y,
+// leaving bg showing around it. Lines of fg that fall below bg are lost.
+func Overlay(bg, fg string, x, y int) string {
+	lines := strings.Split(bg, "\n")
+	for i, line := range strings.Split(fg, "\n") {
+		row := y + i
+		if row < 0 || row >= len(lines) {
+			continue
+		}
+		left := ansi.Truncate(lines[row], x, "")
+		if pad := x - ansi.StringWidth(left); pad > 0 {
+			left += strings.Repeat(" ", pad)
+		}
+		right := ansi.TruncateLeft(lines[row], x+ansi.StringWidth(line), "")
+		lines[row] = left + ansi.ResetStyle + line + ansi.ResetStyle + right
+	}
+	return strings.Join(lines, "\n")
+}
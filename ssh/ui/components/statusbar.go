@@ -9,29 +9,137 @@ import (
 	"terminull-ssh/ui/theme"
 )
 
+// Status is what the status bar shows.
+type Status struct {
+	Page    string
+	Volume  *int
+	Section string // the section of the page being read, if any
+	Find    string // progress of a find on the page; replaces the key hints
+	Alert   string // replaces the key hints, e.g. a timeout countdown
+	Rec     bool   // the session is being recorded
+}
+
 // RenderStatusBar renders the bottom status line.
-// Format: terminull // vol.N [ PAGE ]     ? help | j/k nav | / search
-func RenderStatusBar(page string, volume *int, width int) string {
+// Format: terminull // vol.N [ PAGE ] // SECTION     ? help | j/k nav | / search
+func RenderStatusBar(st Status, width int) string {
+	barStyle := lipgloss.NewStyle().
+		Foreground(theme.Secondary).
+		Background(theme.BgSurface)
+
 	left := "terminull"
-	if volume != nil {
-		left += fmt.Sprintf(" // vol.%d", *volume)
+	if st.Volume != nil {
+		left += fmt.Sprintf(" // vol.%d", *st.Volume)
 	}
-	if page != "" {
-		left += fmt.Sprintf(" [ %s ]", page)
+	if st.Page != "" {
+		left += fmt.Sprintf(" [ %s ]", st.Page)
+	}
+	if st.Section != "" {
+		left += " // " + st.Section
+	}
+	rec := ""
+	if st.Rec {
+		rec = "● REC "
 	}
 
 	right := "? help | j/k nav | / search"
+	rightStyle := barStyle
+	if st.Find != "" {
+		right = Truncate(st.Find, width/2)
+		rightStyle = barStyle.Foreground(theme.Cyan).Bold(true)
+	}
+	if st.Alert != "" {
+		right = Truncate(st.Alert, width-1)
+		rightStyle = barStyle.Foreground(theme.Gold).Bold(true)
+	}
 
-	gap := width - lipgloss.Width(left) - lipgloss.Width(right)
+	// On a narrow terminal the hints go first, then the page name is cut
+	// short. An alert or a find's progress is never dropped.
+	room := width - lipgloss.Width(rec) - 1
+	if st.Alert == "" && st.Find == "" && lipgloss.Width(left)+lipgloss.Width(right) > room {
+		right = ""
+	}
+	left = Truncate(left, max(room-lipgloss.Width(right), 0))
+
+	bar := barStyle.Foreground(theme.Red).Bold(true).Render(rec) + barStyle.Render(left)
+	gap := width - lipgloss.Width(bar) - lipgloss.Width(right)
 	if gap < 1 {
 		gap = 1
 	}
 
-	bar := left + strings.Repeat(" ", gap) + right
+	return bar + barStyle.Render(strings.Repeat(" ", gap)) + rightStyle.Render(right)
+}
+
+// RenderBanner returns a one-line notice shown above the status bar, such
+// as a sysop broadcast, with an optional hint at its right edge.
+func RenderBanner(text, hint string, width int) string {
+	left := text
+	right := hint
+
+	avail := width - lipgloss.Width(right) - 1
+	if lipgloss.Width(left) > avail {
+		left = truncateWidth(left, avail)
+	}
+	gap := width - lipgloss.Width(left) - lipgloss.Width(right)
+	if gap < 1 {
+		gap = 1
+	}
 
 	return lipgloss.NewStyle().
-		Foreground(theme.Secondary).
-		Background(theme.BgSurface).
+		Foreground(theme.BgDeep).
+		Background(theme.Gold).
+		Bold(true).
 		Width(width).
-		Render(bar)
+		Render(left + strings.Repeat(" ", gap) + right)
+}
+
+// RenderNotice returns a full-screen titled message, used when a session
+// is about to end.
+func RenderNotice(title, text string, width int) string {
+	w := width
+	if w > theme.MaxWidth {
+		w = theme.MaxWidth
+	}
+	titleStyle := lipgloss.NewStyle().Foreground(theme.Gold).Bold(true)
+	textStyle := lipgloss.NewStyle().Foreground(theme.Text).Width(w)
+	return "\n" + titleStyle.Render(title) + "\n" + RenderDivider(w) + "\n\n" + textStyle.Render(text) + "\n"
+}
+
+// Truncate cuts plain text s to at most width cells, marking a cut with
+// "…".
+func Truncate(s string, width int) string {
+	if lipgloss.Width(s) <= width {
+		return s
+	}
+	return truncateWidth(s, width)
+}
+
+// RenderTooSmall asks the caller to enlarge a terminal smaller than
+// minWidth x minHeight, in whatever room there is.
+func RenderTooSmall(width, height, minWidth, minHeight int) string {
+	titleStyle := lipgloss.NewStyle().Foreground(theme.Gold).Bold(true)
+	textStyle := lipgloss.NewStyle().Foreground(theme.Text).Width(max(width, 1))
+	hintStyle := lipgloss.NewStyle().Foreground(theme.Muted)
+	text := fmt.Sprintf("Please enlarge your terminal to at least %dx%d. It is %dx%d now.",
+		minWidth, minHeight, width, height)
+	return lipgloss.NewStyle().MaxWidth(width).MaxHeight(height).Render(
+		titleStyle.Render("TERMINAL TOO SMALL") + "\n\n" + textStyle.Render(text) + "\n\n" +
+			hintStyle.Render("Ctrl+C to disconnect"))
+}
+
+// truncateWidth cuts s to at most width cells, marking the cut with "…".
+func truncateWidth(s string, width int) string {
+	if width <= 1 {
+		return ""
+	}
+	var b strings.Builder
+	w := 0
+	for _, r := range s {
+		rw := lipgloss.Width(string(r))
+		if w+rw > width-1 {
+			break
+		}
+		b.WriteRune(r)
+		w += rw
+	}
+	return b.String() + "…"
 }
@@ -0,0 +1,93 @@
+// Package layout decides how screens arrange themselves for the caller's
+// terminal size: a pared-down compact layout for small terminals, the
+// usual single column, or a wide layout with a sidebar.
+package layout
+
+import "terminull-ssh/ui/theme"
+
+// Mode is a screen arrangement.
+type Mode int
+
+const (
+	Compact Mode = iota // small terminal: no logo or decoration, short labels
+	Normal              // one column, at most theme.MaxWidth wide
+	Wide                // a sidebar beside a full-width column
+)
+
+// Terminal size bounds. They are set from the configuration at startup.
+var (
+	// MinWidth and MinHeight are the smallest terminal the BBS draws in.
+	// Below them callers are asked to enlarge their terminal. They cannot
+	// be set lower than the defaults, the smallest the screens fit in.
+	MinWidth  = 40
+	MinHeight = 10
+
+	// MaxWidth and MaxHeight are the largest terminal the BBS lays itself
+	// out for. Larger terminals are drawn in as if they were this size.
+	MaxWidth  = 300
+	MaxHeight = 100
+)
+
+const (
+	// Terminals narrower or shorter than this get the compact layout.
+	compactWidth  = 60
+	compactHeight = 16
+
+	// SidebarWidth is the width of the wide layout's sidebar, not counting
+	// the gutter between it and the content.
+	SidebarWidth = 28
+	gutter       = 2
+)
+
+// Layout is the arrangement for one size of screen.
+type Layout struct {
+	Mode    Mode
+	Width   int // columns available to the screen
+	Height  int // rows available to the screen
+	Content int // columns the main content is laid out in
+	Sidebar int // sidebar columns in the wide layout, else 0
+}
+
+// For returns the layout for a screen of the given size.
+func For(width, height int) Layout {
+	l := Layout{Mode: Normal, Width: width, Height: height, Content: min(width, theme.MaxWidth)}
+	switch {
+	case width < compactWidth || height < compactHeight:
+		l.Mode = Compact
+	case width >= theme.MaxWidth+SidebarWidth+gutter:
+		l.Mode = Wide
+		l.Sidebar = SidebarWidth
+	}
+	return l
+}
+
+// Gutter returns the spaces between the sidebar and the content.
+func (l Layout) Gutter() int {
+	if l.Mode != Wide {
+		return 0
+	}
+	return gutter
+}
+
+// Fit returns the size to lay out a terminal of the given size in, no
+// larger than MaxWidth by MaxHeight.
+func Fit(width, height int) (int, int) {
+	return min(width, MaxWidth), min(height, MaxHeight)
+}
+
+// TooSmall reports whether a terminal of the given size is below the
+// minimum.
+func TooSmall(width, height int) bool {
+	return width < MinWidth || height < MinHeight
+}
+
+// Window returns the range [first, last) of n list items to show in
+// visible rows, keeping the cursor in view and roughly centered.
+func Window(n, cursor, visible int) (first, last int) {
+	visible = max(visible, 1)
+	if n <= visible {
+		return 0, n
+	}
+	first = min(max(cursor-visible/2, 0), n-visible)
+	return first, first + visible
+}
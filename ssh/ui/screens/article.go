@@ -1,15 +1,25 @@
 package screens
 
 import (
+	"errors"
 	"fmt"
+	"regexp"
 	"strings"
+	"time"
+	"unicode"
 
+	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 
 	"terminull-ssh/content"
+	"terminull-ssh/ctf"
+	"terminull-ssh/metrics"
+	"terminull-ssh/render"
 	"terminull-ssh/ui/components"
+	"terminull-ssh/ui/layout"
 	"terminull-ssh/ui/theme"
 )
 
@@ -24,9 +34,80 @@ type ArticleScreen struct {
 	width      int
 	height     int
 	siteURL    string
+
+	renders *render.Cache
+	budget  render.Budget
+	body    string     // the rendered markdown, or "" until the first rendering arrives
+	bodyKey render.Key // what body is a rendering of
+	want    render.Key // the rendering being waited for, if body is not it
+
+	scores     *ctf.Scoreboard
+	username   string
+	keyID      string
+	submitting bool
+	flagInput  textinput.Model
+	flagMsg    string
+	flagErr    bool
+
+	sections      []section
+	outline       bool   // the outline overlay is open
+	outlineCursor int    // selected section in the outline
+	pendingKey    string // first key of a two-key command like ]]
+
+	rendered  string // the article as rendered, before find highlighting
+	finding   bool   // the find prompt is open
+	findInput textinput.Model
+	findRe    *regexp.Regexp // the active find, or nil
+	findText  string         // what was searched for
+	matches   []components.Match
+	match     int // index of the current match
+
+	links      []content.Link // numbered as they appear, from 1
+	picking    bool           // the link picker is open
+	linkCursor int            // selected link in the picker
+
+	code       []content.CodeBlock
+	copying    bool // the copy picker is open
+	copyCursor int  // selected entry in the copy picker; past the code blocks is the article's URL
+
+	notice    string // the result of following a link or copying
+	noticeErr bool
 }
 
-func NewArticleScreen(store *content.Store, volNum, articleIdx, width, height int, siteURL string) *ArticleScreen {
+// renderedMsg delivers an article body rendered in the background.
+type renderedMsg struct {
+	key  render.Key
+	body string
+	md   string // what was rendered, to try again
+	err  error
+}
+
+// rerenderMsg asks for the body to be rendered again, once the terminal
+// has stopped changing size.
+type rerenderMsg struct {
+	key render.Key
+	md  string
+}
+
+func (renderedMsg) Background() {}
+func (rerenderMsg) Background() {}
+
+// rerenderDelay is how long a resize must settle before the article is
+// rendered again at the new width.
+const rerenderDelay = 100 * time.Millisecond
+
+// busyRetryDelay is how long to wait before rendering the article again
+// when too many renderings over time were still running.
+const busyRetryDelay = time.Second
+
+// section is a heading of the article and the viewport line it was
+// rendered on.
+type section struct {
+	content.Heading
+	line int
+}
+
+func NewArticleScreen(store *content.Store, scores *ctf.Scoreboard, volNum, articleIdx, width, height int, username, keyID, siteURL string, renders *render.Cache, budget render.Budget) *ArticleScreen {
 	var vol *content.Volume
 	for i := range store.Volumes {
 		if store.Volumes[i].Number == volNum {
@@ -38,8 +119,26 @@ func NewArticleScreen(store *content.Store, volNum, articleIdx, width, height in
 	var article *content.Article
 	if vol != nil && articleIdx >= 0 && articleIdx < len(vol.Articles) {
 		article = &vol.Articles[articleIdx]
+		metrics.ArticleOpened(volNum, article.Slug)
 	}
 
+	// Flags are masked so they never appear on screen.
+	fi := textinput.New()
+	fi.Placeholder = "flag{...}"
+	fi.CharLimit = 256
+	fi.EchoMode = textinput.EchoPassword
+	fi.EchoCharacter = '•'
+	fi.TextStyle = lipgloss.NewStyle().Foreground(theme.Text)
+	fi.PromptStyle = lipgloss.NewStyle().Foreground(theme.Pink)
+	fi.Prompt = "flag> "
+
+	fin := textinput.New()
+	fin.Placeholder = "find in article"
+	fin.CharLimit = 128
+	fin.TextStyle = lipgloss.NewStyle().Foreground(theme.Text)
+	fin.PromptStyle = lipgloss.NewStyle().Foreground(theme.Gold)
+	fin.Prompt = "/"
+
 	a := &ArticleScreen{
 		store:      store,
 		volNum:     volNum,
@@ -49,6 +148,13 @@ func NewArticleScreen(store *content.Store, volNum, articleIdx, width, height in
 		width:      width,
 		height:     height,
 		siteURL:    siteURL,
+		renders:    renders,
+		budget:     budget,
+		scores:     scores,
+		username:   username,
+		keyID:      keyID,
+		flagInput:  fi,
+		findInput:  fin,
 	}
 	a.initViewport()
 	return a
@@ -57,7 +163,6 @@ func NewArticleScreen(store *content.Store, volNum, articleIdx, width, height in
 func (a *ArticleScreen) initViewport() {
 	a.viewport = viewport.New(a.contentWidth(), a.viewportHeight())
 	a.viewport.Style = lipgloss.NewStyle()
-	a.renderContent()
 }
 
 func (a *ArticleScreen) viewportHeight() int {
@@ -69,7 +174,7 @@ func (a *ArticleScreen) viewportHeight() int {
 }
 
 func (a *ArticleScreen) Init() tea.Cmd {
-	return nil
+	return a.renderContent()
 }
 
 func (a *ArticleScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -79,16 +184,122 @@ func (a *ArticleScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		a.height = msg.Height
 		a.viewport.Width = a.contentWidth()
 		a.viewport.Height = a.viewportHeight()
-		a.renderContent()
-		return a, nil
+		return a, a.renderContent()
+
+	case renderedMsg:
+		if msg.key != a.want {
+			return a, nil // since superseded
+		}
+		if errors.Is(msg.err, render.ErrBusy) {
+			// Show the plain text for now, but render it properly once
+			// the overdue renderings have finished.
+			if a.body == "" {
+				a.body = msg.body
+			}
+			key, md := msg.key, msg.md
+			return a, tea.Batch(a.renderContent(), tea.Tick(busyRetryDelay, func(time.Time) tea.Msg { return rerenderMsg{key, md} }))
+		}
+		a.body, a.bodyKey, a.want = msg.body, msg.key, render.Key{}
+		return a, a.renderContent()
+
+	case rerenderMsg:
+		if msg.key != a.want {
+			return a, nil
+		}
+		return a, a.renderBody(msg.key, msg.md)
 
 	case tea.KeyMsg:
-		switch msg.String() {
+		if a.submitting {
+			return a, a.updateFlag(msg)
+		}
+		if a.outline {
+			a.updateOutline(msg)
+			return a, nil
+		}
+		if a.finding {
+			return a, a.updateFind(msg)
+		}
+		if a.picking {
+			return a, a.updatePicker(msg)
+		}
+		if a.copying {
+			return a, a.updateCopier(msg)
+		}
+		a.flagMsg = ""
+		a.notice = ""
+
+		// ]] and [[ jump between sections.
+		key, pending := msg.String(), a.pendingKey
+		a.pendingKey = ""
+		switch {
+		case (key == "]" || key == "[") && pending == "":
+			a.pendingKey = key
+			return a, nil
+		case key == "]" && pending == "]":
+			a.jumpSection(1)
+			return a, nil
+		case key == "[" && pending == "[":
+			a.jumpSection(-1)
+			return a, nil
+		}
+
+		// While a find is active n and N move between matches, as in less,
+		// and Esc clears it.
+		if a.findRe != nil {
+			switch key {
+			case "n":
+				a.nextMatch(1)
+				return a, nil
+			case "N":
+				a.nextMatch(-1)
+				return a, nil
+			case "esc":
+				a.clearFind()
+				return a, nil
+			}
+		}
+
+		switch key {
+		case "l":
+			if len(a.links) > 0 {
+				a.picking = true
+				a.linkCursor = 0
+			}
+			return a, nil
+		case "c":
+			if a.article != nil {
+				a.copying = true
+				a.copyCursor = 0
+			}
+			return a, nil
+		case "y":
+			if a.article != nil {
+				return a, a.copyURL()
+			}
+			return a, nil
+		case "/":
+			a.finding = true
+			a.findInput.Reset()
+			return a, a.findInput.Focus()
+		case "o":
+			if len(a.sections) > 0 {
+				a.outline = true
+				a.outlineCursor = max(a.currentSection(), 0)
+			}
+			return a, nil
+		case "F":
+			if a.article == nil || len(a.article.Challenges) == 0 || a.scores == nil {
+				return a, nil
+			}
+			a.submitting = true
+			a.flagMsg = ""
+			a.flagInput.Reset()
+			return a, a.flagInput.Focus()
 		case "q", "esc":
 			return a, backCmd()
 		case "?":
 			return a, navigateCmd("help", 0, 0, "", "")
-		case "/":
+		case "s":
 			return a, navigateCmd("search", 0, 0, "", "")
 		case "p":
 			if a.articleIdx > 0 {
@@ -114,19 +325,360 @@ func (a *ArticleScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return a, cmd
 }
 
-func (a *ArticleScreen) contentWidth() int {
-	w := a.width
-	if w > 78 {
-		w = 78
+// updateFind handles keys while the find prompt is open. An empty pattern
+// repeats the last find. Patterns are matched literally, ignoring case
+// unless they contain a capital letter.
+func (a *ArticleScreen) updateFind(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		a.finding = false
+		a.findInput.Blur()
+		return nil
+	case "enter":
+		a.finding = false
+		a.findInput.Blur()
+		text := a.findInput.Value()
+		if text == "" {
+			text = a.findText
+		}
+		if text == "" {
+			return nil
+		}
+		pattern := regexp.QuoteMeta(text)
+		if strings.ToLower(text) == text {
+			pattern = "(?i)" + pattern
+		}
+		a.findText = text
+		a.findRe = regexp.MustCompile(pattern)
+		a.match = 0
+		a.applyFind()
+		// Start from the first match on screen or after it.
+		for i, m := range a.matches {
+			if m.Line >= a.viewport.YOffset {
+				a.match = i
+				break
+			}
+		}
+		a.showMatch()
+		return nil
 	}
-	return w
+
+	var cmd tea.Cmd
+	a.findInput, cmd = a.findInput.Update(msg)
+	return cmd
 }
 
-func (a *ArticleScreen) renderContent() {
-	if a.article == nil {
-		a.viewport.SetContent("Article not found.")
+// applyFind shows the rendered article with the active find's matches
+// highlighted.
+func (a *ArticleScreen) applyFind() {
+	if a.findRe == nil {
+		a.matches = nil
+		a.viewport.SetContent(a.rendered)
+		return
+	}
+	a.matches = components.FindAll(a.rendered, a.findRe)
+	if a.match >= len(a.matches) {
+		a.match = 0
+	}
+	a.viewport.SetContent(components.Highlight(a.rendered, a.matches, a.match))
+}
+
+// nextMatch moves to the next (dir 1) or previous (dir -1) match, wrapping
+// around at either end.
+func (a *ArticleScreen) nextMatch(dir int) {
+	if len(a.matches) == 0 {
+		return
+	}
+	a.match = (a.match + dir + len(a.matches)) % len(a.matches)
+	a.viewport.SetContent(components.Highlight(a.rendered, a.matches, a.match))
+	a.showMatch()
+}
+
+// showMatch scrolls the current match into view if it is not already.
+func (a *ArticleScreen) showMatch() {
+	if len(a.matches) == 0 {
+		return
+	}
+	line := a.matches[a.match].Line
+	if line < a.viewport.YOffset || line >= a.viewport.YOffset+a.viewport.Height {
+		a.viewport.SetYOffset(max(line-a.viewport.Height/3, 0))
+	}
+}
+
+// clearFind ends the active find.
+func (a *ArticleScreen) clearFind() {
+	a.findRe = nil
+	a.applyFind()
+}
+
+// FindStatus reports where the reader is among the matches of the
+// active find.
+func (a *ArticleScreen) FindStatus() string {
+	switch {
+	case a.findRe == nil:
+		return ""
+	case len(a.matches) == 0:
+		return "pattern not found: " + a.findText
+	}
+	return fmt.Sprintf("match %d/%d", a.match+1, len(a.matches))
+}
+
+// updatePicker handles keys while the link picker is open. A digit
+// follows that link at once, as on the list screens.
+func (a *ArticleScreen) updatePicker(msg tea.KeyMsg) tea.Cmd {
+	switch key := msg.String(); key {
+	case "j", "down":
+		if a.linkCursor < len(a.links)-1 {
+			a.linkCursor++
+		}
+	case "k", "up":
+		if a.linkCursor > 0 {
+			a.linkCursor--
+		}
+	case "g", "home":
+		a.linkCursor = 0
+	case "G", "end":
+		a.linkCursor = len(a.links) - 1
+	case "enter":
+		a.picking = false
+		return a.follow(a.links[a.linkCursor])
+	case "1", "2", "3", "4", "5", "6", "7", "8", "9":
+		if i := int(key[0] - '1'); i < len(a.links) {
+			a.picking = false
+			return a.follow(a.links[i])
+		}
+	case "esc", "l", "q":
+		a.picking = false
+	}
+	return nil
+}
+
+// follow opens an internal link in the BBS, or asks the caller's terminal
+// to copy an external one to the clipboard (OSC 52).
+func (a *ArticleScreen) follow(l content.Link) tea.Cmd {
+	t, kind := a.store.Resolve(l.URL, a.siteURL)
+	switch kind {
+	case content.LinkBroken:
+		a.notice, a.noticeErr = "Broken link: "+l.URL, true
+		return nil
+	case content.LinkExternal:
+		url := a.absoluteURL(l.URL)
+		return a.copy(url, url)
+	}
+
+	switch t.Screen {
+	case "section":
+		if i := a.section(t.Anchor); i >= 0 {
+			a.viewport.SetYOffset(a.sections[i].line)
+		} else {
+			a.notice, a.noticeErr = "Broken link: no section #"+t.Anchor, true
+		}
+		return nil
+	case "article":
+		if t.Volume == a.volNum && t.Article == a.articleIdx {
+			a.viewport.GotoTop()
+			return nil
+		}
+		return navigateCmd("article", t.Volume, t.Article, "", "")
+	case "volume":
+		return navigateCmd("volume", t.Volume, 0, "", "")
+	case "page":
+		return navigateCmd("page", 0, 0, t.Page, "")
+	}
+	return navigateCmd(t.Screen, 0, 0, "", "")
+}
+
+// updateCopier handles keys while the copy picker is open.
+func (a *ArticleScreen) updateCopier(msg tea.KeyMsg) tea.Cmd {
+	last := len(a.code) // the article's URL
+	switch key := msg.String(); key {
+	case "j", "down":
+		if a.copyCursor < last {
+			a.copyCursor++
+		}
+	case "k", "up":
+		if a.copyCursor > 0 {
+			a.copyCursor--
+		}
+	case "g", "home":
+		a.copyCursor = 0
+	case "G", "end":
+		a.copyCursor = last
+	case "enter":
+		a.copying = false
+		return a.copyEntry(a.copyCursor)
+	case "1", "2", "3", "4", "5", "6", "7", "8", "9":
+		if i := int(key[0] - '1'); i < len(a.code) {
+			a.copying = false
+			return a.copyEntry(i)
+		}
+	case "y":
+		a.copying = false
+		return a.copyURL()
+	case "esc", "c", "q":
+		a.copying = false
+	}
+	return nil
+}
+
+// copyEntry copies code block i, or the article's URL past the last one.
+func (a *ArticleScreen) copyEntry(i int) tea.Cmd {
+	if i >= len(a.code) {
+		return a.copyURL()
+	}
+	c := a.code[i]
+	n := strings.Count(c.Code, "\n") + 1
+	what := fmt.Sprintf("code block %d (%d lines)", i+1, n)
+	if n == 1 {
+		what = fmt.Sprintf("code block %d (1 line)", i+1)
+	}
+	return a.copy(c.Code, what)
+}
+
+// copyURL copies the address of the article on the site.
+func (a *ArticleScreen) copyURL() tea.Cmd {
+	url := content.ArticleURL(a.siteURL, a.volNum, a.article.Slug)
+	return a.copy(url, url)
+}
+
+// copy returns a command asking the caller's terminal to put text on the
+// clipboard (OSC 52); what describes it to the caller.
+func (a *ArticleScreen) copy(text, what string) tea.Cmd {
+	a.notice, a.noticeErr = "Copied to clipboard: "+what, false
+	return clipboardCmd(text)
+}
+
+// linkKind says where l leads, counting a link to a section this article
+// does not have as broken.
+func (a *ArticleScreen) linkKind(l content.Link) content.LinkKind {
+	t, kind := a.store.Resolve(l.URL, a.siteURL)
+	if t.Screen == "section" && a.section(t.Anchor) < 0 {
+		return content.LinkBroken
+	}
+	return kind
+}
+
+// section returns the index of the section with the given anchor, or -1.
+func (a *ArticleScreen) section(anchor string) int {
+	for i, s := range a.sections {
+		if content.HeadingSlug(s.Text) == anchor {
+			return i
+		}
+	}
+	return -1
+}
+
+// absoluteURL makes a link to a file on the site into a full URL.
+func (a *ArticleScreen) absoluteURL(url string) string {
+	if strings.HasPrefix(url, "/") && !strings.HasPrefix(url, "//") {
+		return strings.TrimSuffix(a.siteURL, "/") + url
+	}
+	return url
+}
+
+// updateOutline handles keys while the outline is open.
+func (a *ArticleScreen) updateOutline(msg tea.KeyMsg) {
+	switch msg.String() {
+	case "j", "down":
+		if a.outlineCursor < len(a.sections)-1 {
+			a.outlineCursor++
+		}
+	case "k", "up":
+		if a.outlineCursor > 0 {
+			a.outlineCursor--
+		}
+	case "g", "home":
+		a.outlineCursor = 0
+	case "G", "end":
+		a.outlineCursor = len(a.sections) - 1
+	case "enter":
+		a.viewport.SetYOffset(a.sections[a.outlineCursor].line)
+		a.outline = false
+	case "esc", "o", "q":
+		a.outline = false
+	}
+}
+
+// currentSection returns the index of the section at the top of the
+// viewport, or -1 above the first heading.
+func (a *ArticleScreen) currentSection() int {
+	cur := -1
+	for i, s := range a.sections {
+		if s.line > a.viewport.YOffset {
+			break
+		}
+		cur = i
+	}
+	return cur
+}
+
+// jumpSection scrolls to the next (dir 1) or previous (dir -1) heading.
+func (a *ArticleScreen) jumpSection(dir int) {
+	y := a.viewport.YOffset
+	if dir > 0 {
+		for _, s := range a.sections {
+			if s.line > y {
+				a.viewport.SetYOffset(s.line)
+				return
+			}
+		}
 		return
 	}
+	for i := len(a.sections) - 1; i >= 0; i-- {
+		if a.sections[i].line < y {
+			a.viewport.SetYOffset(a.sections[i].line)
+			return
+		}
+	}
+}
+
+// updateFlag handles keys while the flag prompt is open.
+func (a *ArticleScreen) updateFlag(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		a.submitting = false
+		a.flagInput.Blur()
+		return nil
+	case "enter":
+		a.submitting = false
+		a.flagInput.Blur()
+		flag := a.flagInput.Value()
+		a.flagInput.Reset()
+
+		solved, already, err := a.scores.Submit(a.article.Challenges, a.keyID, a.username, flag)
+		switch {
+		case err != nil:
+			a.flagMsg, a.flagErr = err.Error(), true
+		case solved == nil:
+			a.flagMsg, a.flagErr = "Wrong flag.", true
+		case already:
+			a.flagMsg, a.flagErr = fmt.Sprintf("Already solved %s.", solved.Title), false
+		default:
+			a.flagMsg, a.flagErr = fmt.Sprintf("Correct! %s solved, +%d points.", solved.Title, solved.Points), false
+			return a.renderContent()
+		}
+		return nil
+	}
+
+	var cmd tea.Cmd
+	a.flagInput, cmd = a.flagInput.Update(msg)
+	return cmd
+}
+
+func (a *ArticleScreen) contentWidth() int {
+	return layout.For(a.width, a.height).Content
+}
+
+// renderContent lays the article out around its rendered body. A body
+// not in the shared cache is rendered in the background by the command
+// returned; until it arrives the article shows the body it has, or a
+// loading line if it has none yet.
+func (a *ArticleScreen) renderContent() tea.Cmd {
+	if a.article == nil {
+		a.rendered = "Article not found."
+		a.applyFind()
+		return nil
+	}
 
 	w := a.contentWidth()
 	var b strings.Builder
@@ -152,18 +704,57 @@ func (a *ArticleScreen) renderContent() {
 	b.WriteString(components.RenderBoxFrame(metaTitle, metaLines, w))
 	b.WriteString("\n\n")
 
+	// Attached CTF challenges
+	if len(a.article.Challenges) > 0 && a.scores != nil {
+		var lines []string
+		for _, c := range a.article.Challenges {
+			mark := "[ ]"
+			if a.scores.Solved(a.keyID, c.ID) {
+				mark = "[✓]"
+			}
+			lines = append(lines, fmt.Sprintf("%s %s  (%d pts)", mark, c.Title, c.Points))
+		}
+		lines = append(lines, "", "Press F to submit a flag.")
+		b.WriteString(components.RenderBoxFrame("CHALLENGES", lines, w))
+		b.WriteString("\n\n")
+	}
+
 	// Render markdown body
 	preprocessed := content.PreprocessMarkdown(a.article.Body, a.siteURL, a.volNum, a.article.Slug)
-	renderer, err := theme.NewGlamourRenderer(w - 2)
-	if err == nil {
-		rendered, err := renderer.Render(preprocessed)
-		if err == nil {
-			b.WriteString(rendered)
-		} else {
-			b.WriteString(preprocessed)
-		}
+	numbered, links := content.NumberLinks(preprocessed)
+	a.links = links
+	a.code = content.CodeBlocks(a.article.Body)
+
+	var cmd tea.Cmd
+	key := markdownKey(fmt.Sprintf("vol%d/%s", a.volNum, a.article.Slug), numbered, w-2)
+	switch body, ok := a.renders.Get(key); {
+	case key == a.bodyKey:
+	case ok:
+		metrics.RenderCache.WithLabelValues("hit").Inc()
+		a.body, a.bodyKey, a.want = body, key, render.Key{}
+	case key == a.want:
+		// Already on its way.
+	case a.body == "":
+		a.want = key
+		cmd = a.renderBody(key, numbered)
+	default:
+		// Keep showing the old body while the terminal is being resized.
+		a.want = key
+		cmd = tea.Tick(rerenderDelay, func(time.Time) tea.Msg { return rerenderMsg{key, numbered} })
+	}
+
+	if a.body == "" {
+		a.sections = nil
+		b.WriteString(lipgloss.NewStyle().Foreground(theme.Muted).Render("  Rendering article..."))
+		b.WriteString("\n\n")
 	} else {
-		b.WriteString(preprocessed)
+		a.sections = locateSections(content.Outline(preprocessed), a.body, strings.Count(b.String(), "\n"))
+		b.WriteString(a.body)
+	}
+
+	if len(a.links) > 0 {
+		b.WriteString(a.renderReferences(w))
+		b.WriteString("\n")
 	}
 
 	b.WriteString("\n")
@@ -190,11 +781,285 @@ func (a *ArticleScreen) renderContent() {
 	b.WriteString(navStyle.Render("  [q] back to table of contents"))
 	b.WriteString("\n")
 
-	a.viewport.SetContent(b.String())
+	a.rendered = b.String()
+	a.applyFind()
+	return cmd
+}
+
+// renderBody returns a command rendering md, through the shared cache,
+// and delivering the result. Markdown that cannot be rendered is not
+// cached; the budget remembers what was over it, so it is not tried again.
+func (a *ArticleScreen) renderBody(key render.Key, md string) tea.Cmd {
+	cache, budget := a.renders, a.budget
+	return func() tea.Msg {
+		body, err := cache.Render(key, func() (string, error) {
+			metrics.RenderCache.WithLabelValues("miss").Inc()
+			return renderMarkdown("article", key, md, budget)
+		})
+		return renderedMsg{key, body, md, err}
+	}
+}
+
+// renderReferences lists the article's links by number. External links
+// are OSC 8 hyperlinks, so terminals that support them can open them.
+func (a *ArticleScreen) renderReferences(w int) string {
+	titleStyle := lipgloss.NewStyle().Foreground(theme.Gold).Bold(true)
+	numStyle := lipgloss.NewStyle().Foreground(theme.Green)
+	textStyle := lipgloss.NewStyle().Foreground(theme.Text)
+	urlStyle := lipgloss.NewStyle().Foreground(theme.Cyan)
+	mutedStyle := lipgloss.NewStyle().Foreground(theme.Muted)
+	badStyle := lipgloss.NewStyle().Foreground(theme.Red)
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("LINKS"))
+	b.WriteString("\n")
+	b.WriteString(components.RenderDivider(w))
+	b.WriteString("\n")
+	for i, l := range a.links {
+		num := fmt.Sprintf("  [%d] ", i+1)
+		b.WriteString(numStyle.Render(num) + textStyle.Render(components.Truncate(l.Text, w-len(num))) + "\n")
+
+		indent := strings.Repeat(" ", len(num))
+		url := components.Truncate(a.absoluteURL(l.URL), w-len(num))
+		switch a.linkKind(l) {
+		case content.LinkBroken:
+			b.WriteString(indent + badStyle.Render(components.Truncate(l.URL+"  (broken link)", w-len(num))))
+		case content.LinkInternal:
+			b.WriteString(indent + urlStyle.Render(url))
+		default:
+			b.WriteString(indent + ansi.SetHyperlink(a.absoluteURL(l.URL)) + urlStyle.Render(url) + ansi.ResetHyperlink())
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+	b.WriteString(mutedStyle.Render("  Press l to follow a link."))
+	b.WriteString("\n")
+	return b.String()
+}
+
+// locateSections finds the line each heading was rendered on, counting
+// from first, the line body starts on. Headings are looked for in order,
+// so a paragraph repeating a heading's words is not mistaken for it; any
+// that cannot be found are left out.
+func locateSections(headings []content.Heading, body string, first int) []section {
+	lines := strings.Split(ansi.Strip(body), "\n")
+	var out []section
+	next := 0
+	for _, h := range headings {
+		want := headingKey(h.Text)
+		for i := next; i < len(lines); i++ {
+			// Headings may be prefixed with #s and wrapped onto more lines.
+			got := headingKey(strings.TrimLeft(strings.TrimSpace(lines[i]), "# "))
+			if got == "" || len(got) < min(len(want), 12) {
+				continue
+			}
+			// got may also run on past want with a link's reference number.
+			if !strings.HasPrefix(want, got) && !strings.HasPrefix(got, want) {
+				continue
+			}
+			out = append(out, section{Heading: h, line: first + i})
+			next = i + 1
+			break
+		}
+	}
+	return out
+}
+
+// headingKey reduces a heading to lowercase letters and digits, to compare
+// markdown with what the renderer made of it.
+func headingKey(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, s)
 }
 
 func (a *ArticleScreen) View() string {
-	return a.viewport.View()
+	body := a.viewport.View()
+	switch {
+	case a.outline:
+		body = a.renderOutline(body)
+	case a.picking:
+		body = a.renderPicker(body)
+	case a.copying:
+		body = a.renderCopier(body)
+	}
+	if l := layout.For(a.width, a.height); l.Mode == layout.Wide {
+		body = lipgloss.JoinHorizontal(lipgloss.Top,
+			a.renderSidebar(l.Sidebar, a.viewport.Height), strings.Repeat(" ", l.Gutter()), body)
+	}
+
+	switch {
+	case a.submitting:
+		return body + "\n" + a.flagInput.View()
+	case a.finding:
+		return body + "\n" + a.findInput.View()
+	case a.notice != "":
+		color := theme.Green
+		if a.noticeErr {
+			color = theme.Red
+		}
+		msg := lipgloss.NewStyle().Foreground(color).Render(components.Truncate(a.notice, a.width))
+		return body + "\n" + msg
+	case a.flagMsg != "":
+		color := theme.Green
+		if a.flagErr {
+			color = theme.Red
+		}
+		return body + "\n" + lipgloss.NewStyle().Foreground(color).Render(a.flagMsg)
+	}
+	return body
+}
+
+// renderSidebar renders the volume's table of contents beside the article
+// in the wide layout, with the article being read highlighted.
+func (a *ArticleScreen) renderSidebar(w, rows int) string {
+	if a.volume == nil {
+		return lipgloss.NewStyle().Width(w).Render("")
+	}
+	titleStyle := lipgloss.NewStyle().Foreground(theme.Gold).Bold(true)
+	numStyle := lipgloss.NewStyle().Foreground(theme.Green)
+	itemStyle := lipgloss.NewStyle().Foreground(theme.Secondary)
+	activeStyle := lipgloss.NewStyle().Foreground(theme.GreenBright).Bold(true)
+	hintStyle := lipgloss.NewStyle().Foreground(theme.Muted)
+
+	out := []string{
+		titleStyle.Render(fmt.Sprintf("VOLUME %d", a.volNum)),
+		components.RenderDivider(w),
+	}
+	// Keep the current article in view, leaving room for the key hint.
+	arts := a.volume.Articles
+	first, last := layout.Window(len(arts), a.articleIdx, rows-len(out)-2)
+	for i := first; i < last; i++ {
+		num := fmt.Sprintf("%02d ", arts[i].Order)
+		title := components.Truncate(arts[i].Title, w-2-len(num))
+		if i == a.articleIdx {
+			out = append(out, activeStyle.Render("▸ "+num+title))
+		} else {
+			out = append(out, "  "+numStyle.Render(num)+itemStyle.Render(title))
+		}
+	}
+	out = append(out, "", hintStyle.Render("p/n prev/next article"))
+	return lipgloss.NewStyle().Width(w).Render(strings.Join(out, "\n"))
+}
+
+// renderOutline draws the article's headings over the viewport, with the
+// one under the cursor highlighted.
+func (a *ArticleScreen) renderOutline(bg string) string {
+	w := min(a.viewport.Width-4, 60)
+	rows := a.viewport.Height - 2 // the frame's top and bottom
+	top := a.sections[0].Level
+	for _, s := range a.sections {
+		top = min(top, s.Level)
+	}
+
+	activeStyle := lipgloss.NewStyle().Foreground(theme.GreenBright).Bold(true)
+	itemStyle := lipgloss.NewStyle().Foreground(theme.Text)
+	hintStyle := lipgloss.NewStyle().Foreground(theme.Muted)
+
+	var lines []string
+	first, last := layout.Window(len(a.sections), a.outlineCursor, rows-2)
+	for i := first; i < last; i++ {
+		s := a.sections[i]
+		indent := strings.Repeat("  ", s.Level-top)
+		text := components.Truncate(s.Text, w-6-len(indent))
+		if i == a.outlineCursor {
+			lines = append(lines, activeStyle.Render("▸ "+indent+text))
+		} else {
+			lines = append(lines, "  "+indent+itemStyle.Render(text))
+		}
+	}
+	lines = append(lines, "", hintStyle.Render("Enter jump | j/k move | Esc close"))
+	box := components.RenderBoxFrame("OUTLINE", lines, w)
+	return components.Overlay(bg, box, (a.viewport.Width-w)/2, 1)
+}
+
+// renderPicker draws the article's links over the viewport for the caller
+// to choose one to follow.
+func (a *ArticleScreen) renderPicker(bg string) string {
+	w := min(a.viewport.Width-4, 70)
+	rows := a.viewport.Height - 2
+
+	activeStyle := lipgloss.NewStyle().Foreground(theme.GreenBright).Bold(true)
+	itemStyle := lipgloss.NewStyle().Foreground(theme.Text)
+	badStyle := lipgloss.NewStyle().Foreground(theme.Red)
+	hintStyle := lipgloss.NewStyle().Foreground(theme.Muted)
+
+	var lines []string
+	first, last := layout.Window(len(a.links), a.linkCursor, rows-2)
+	for i := first; i < last; i++ {
+		l := a.links[i]
+		label := fmt.Sprintf("[%d] %s", i+1, l.Text)
+		kind := a.linkKind(l)
+		mark := ""
+		switch kind {
+		case content.LinkBroken:
+			mark = "  broken"
+		case content.LinkExternal:
+			mark = "  ↗"
+		}
+		label = components.Truncate(label, w-6-lipgloss.Width(mark))
+		markStyle := hintStyle
+		if kind == content.LinkBroken {
+			markStyle = badStyle
+		}
+		if i == a.linkCursor {
+			lines = append(lines, activeStyle.Render("▸ "+label)+markStyle.Render(mark))
+		} else {
+			lines = append(lines, "  "+itemStyle.Render(label)+markStyle.Render(mark))
+		}
+	}
+	lines = append(lines, "", hintStyle.Render("Enter follow | ↗ copies the URL | Esc close"))
+	box := components.RenderBoxFrame("LINKS", lines, w)
+	return components.Overlay(bg, box, (a.viewport.Width-w)/2, 1)
+}
+
+// renderCopier draws the article's code blocks, and its URL, over the
+// viewport for the caller to choose one to copy.
+func (a *ArticleScreen) renderCopier(bg string) string {
+	w := min(a.viewport.Width-4, 70)
+	rows := a.viewport.Height - 2
+
+	activeStyle := lipgloss.NewStyle().Foreground(theme.GreenBright).Bold(true)
+	itemStyle := lipgloss.NewStyle().Foreground(theme.Text)
+	langStyle := lipgloss.NewStyle().Foreground(theme.Cyan)
+	hintStyle := lipgloss.NewStyle().Foreground(theme.Muted)
+
+	var lines []string
+	if len(a.code) == 0 {
+		lines = append(lines, hintStyle.Render("  No code blocks in this article."), "")
+		rows -= 2
+	}
+	first, last := layout.Window(len(a.code)+1, a.copyCursor, rows-2)
+	for i := first; i < last; i++ {
+		label, lang := "Article URL", ""
+		if i < len(a.code) {
+			c := a.code[i]
+			label = fmt.Sprintf("[%d] %s", i+1, strings.TrimSpace(strings.SplitN(strings.TrimLeft(c.Code, "\n"), "\n", 2)[0]))
+			if c.Lang != "" {
+				lang = "  " + c.Lang
+			}
+		}
+		label = components.Truncate(label, w-6-lipgloss.Width(lang))
+		if i == a.copyCursor {
+			lines = append(lines, activeStyle.Render("▸ "+label)+langStyle.Render(lang))
+		} else {
+			lines = append(lines, "  "+itemStyle.Render(label)+langStyle.Render(lang))
+		}
+	}
+	lines = append(lines, "", hintStyle.Render("Enter copy | y copy URL | Esc close"))
+	box := components.RenderBoxFrame("COPY", lines, w)
+	return components.Overlay(bg, box, (a.viewport.Width-w)/2, 1)
+}
+
+// Section returns the heading of the part of the article on screen.
+func (a *ArticleScreen) Section() string {
+	if i := a.currentSection(); i >= 0 {
+		return a.sections[i].Text
+	}
+	return ""
 }
 
 func (a *ArticleScreen) StatusInfo() (string, *int) {
@@ -0,0 +1,169 @@
+package screens
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"terminull-ssh/callers"
+	"terminull-ssh/nodes"
+	"terminull-ssh/ui/components"
+	"terminull-ssh/ui/theme"
+)
+
+// CallersScreen lists recent sessions and lets the caller opt out of it.
+type CallersScreen struct {
+	calls  *callers.Log
+	node   *nodes.Node
+	keyID  string
+	width  int
+	height int
+	offset int
+	status string
+}
+
+func NewCallersScreen(calls *callers.Log, node *nodes.Node, keyID string, width, height int) *CallersScreen {
+	return &CallersScreen{
+		calls:  calls,
+		node:   node,
+		keyID:  keyID,
+		width:  width,
+		height: height,
+	}
+}
+
+func (c *CallersScreen) Init() tea.Cmd { return nil }
+
+func (c *CallersScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		c.width = msg.Width
+		c.height = msg.Height
+		return c, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "j", "down":
+			if c.offset < len(c.calls.Recent(100))-c.pageSize() {
+				c.offset++
+			}
+			return c, nil
+		case "k", "up":
+			if c.offset > 0 {
+				c.offset--
+			}
+			return c, nil
+		case "x":
+			c.toggleUnlisted()
+			return c, nil
+		case "q", "esc":
+			return c, backCmd()
+		case "?":
+			return c, navigateCmd("help", 0, 0, "", "")
+		}
+	}
+
+	return c, nil
+}
+
+// toggleUnlisted flips the caller's listing preference. Members keep the
+// choice across sessions; guests only for this call.
+func (c *CallersScreen) toggleUnlisted() {
+	if c.node == nil {
+		return
+	}
+	v := !c.node.Unlisted()
+	c.node.SetUnlisted(v)
+	if err := c.calls.SetOptOut(c.keyID, v); err != nil {
+		c.status = "could not save preference: " + err.Error()
+		return
+	}
+	switch {
+	case v && c.keyID != "":
+		c.status = "Your calls will no longer be listed."
+	case v:
+		c.status = "This call will not be listed."
+	default:
+		c.status = "Your calls will be listed."
+	}
+}
+
+// pageSize is how many rows fit below the header and above the hints.
+func (c *CallersScreen) pageSize() int {
+	n := c.height - 10
+	if n < 3 {
+		n = 3
+	}
+	return n
+}
+
+func (c *CallersScreen) View() string {
+	w := c.width
+	if w > theme.MaxWidth {
+		w = theme.MaxWidth
+	}
+
+	var b strings.Builder
+
+	titleStyle := lipgloss.NewStyle().Foreground(theme.Gold).Bold(true)
+	b.WriteString(titleStyle.Render("LAST CALLERS"))
+	b.WriteString("\n")
+	b.WriteString(components.RenderDivider(w))
+	b.WriteString("\n\n")
+
+	calls := c.calls.Recent(100)
+	if len(calls) == 0 {
+		b.WriteString(lipgloss.NewStyle().Foreground(theme.Secondary).Render("  No callers yet."))
+		b.WriteString("\n")
+	} else {
+		headerStyle := lipgloss.NewStyle().Foreground(theme.Muted)
+		b.WriteString(headerStyle.Render(fmt.Sprintf("  %-18s%-6s%-14s%-10s%s", "HANDLE", "NODE", "CONNECTED", "DURATION", "TYPE")))
+		b.WriteString("\n")
+		b.WriteString(headerStyle.Render("  " + strings.Repeat("─", 56)))
+		b.WriteString("\n")
+
+		end := c.offset + c.pageSize()
+		if end > len(calls) {
+			end = len(calls)
+		}
+		handleStyle := lipgloss.NewStyle().Foreground(theme.Text)
+		metaStyle := lipgloss.NewStyle().Foreground(theme.Secondary)
+		for _, call := range calls[c.offset:end] {
+			kind := lipgloss.NewStyle().Foreground(theme.Muted).Render("guest")
+			if call.Member {
+				kind = lipgloss.NewStyle().Foreground(theme.Green).Render("member")
+			}
+			b.WriteString("  " + handleStyle.Render(fmt.Sprintf("%-18s", truncate(call.Handle, 17))) +
+				metaStyle.Render(fmt.Sprintf("%-6d%-14s%-10s", call.Node,
+					call.Connected.Format("01-02 15:04"), components.FormatDuration(call.Duration))) +
+				kind)
+			b.WriteString("\n")
+		}
+	}
+
+	b.WriteString("\n")
+	listed := "listed"
+	if c.node != nil && c.node.Unlisted() {
+		listed = "unlisted"
+	}
+	infoStyle := lipgloss.NewStyle().Foreground(theme.Secondary)
+	b.WriteString(infoStyle.Render("  No IP addresses are ever stored. You are currently " + listed + "."))
+	b.WriteString("\n")
+	if c.status != "" {
+		b.WriteString("  " + lipgloss.NewStyle().Foreground(theme.Green).Render(c.status))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	hintStyle := lipgloss.NewStyle().Foreground(theme.Muted)
+	b.WriteString(hintStyle.Render("  x toggle listing  |  j/k scroll  |  q back"))
+	b.WriteString("\n")
+
+	return b.String()
+}
+
+func (c *CallersScreen) StatusInfo() (string, *int) {
+	return "LAST CALLERS", nil
+}
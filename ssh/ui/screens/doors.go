@@ -0,0 +1,195 @@
+package screens
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"terminull-ssh/door"
+	"terminull-ssh/ui/components"
+	"terminull-ssh/ui/theme"
+)
+
+// doorExitMsg is delivered when a door hands the terminal back.
+type doorExitMsg struct {
+	name string
+	cmd  *door.Command
+	err  error
+}
+
+// DoorsScreen lists the sysop's doors and launches them in the caller's PTY.
+type DoorsScreen struct {
+	doors    *door.Registry
+	username string
+	node     int
+	width    int
+	height   int
+	cursor   int
+	status   string
+	failed   bool
+}
+
+func NewDoorsScreen(doors *door.Registry, username string, node, width, height int) *DoorsScreen {
+	return &DoorsScreen{
+		doors:    doors,
+		username: username,
+		node:     node,
+		width:    width,
+		height:   height,
+	}
+}
+
+func (d *DoorsScreen) Init() tea.Cmd { return nil }
+
+func (d *DoorsScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		d.width = msg.Width
+		d.height = msg.Height
+		return d, nil
+
+	case doorExitMsg:
+		msg.cmd.Close()
+		switch {
+		case errors.Is(msg.err, door.ErrTimeLimit):
+			d.setStatus(fmt.Sprintf("Time limit reached in %s.", msg.name), true)
+		case msg.err != nil:
+			d.setStatus(fmt.Sprintf("%s exited: %v", msg.name, msg.err), true)
+		default:
+			d.setStatus(fmt.Sprintf("Returned from %s.", msg.name), false)
+		}
+		return d, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "j", "down":
+			if d.cursor < d.doors.Len()-1 {
+				d.cursor++
+			}
+			return d, nil
+		case "k", "up":
+			if d.cursor > 0 {
+				d.cursor--
+			}
+			return d, nil
+		case "enter":
+			return d, d.launch(d.cursor)
+		case "1", "2", "3", "4", "5", "6", "7", "8", "9":
+			idx := int(msg.String()[0]-'0') - 1
+			if idx < d.doors.Len() {
+				d.cursor = idx
+				return d, d.launch(idx)
+			}
+			return d, nil
+		case "q", "esc":
+			return d, backCmd()
+		case "?":
+			return d, navigateCmd("help", 0, 0, "", "")
+		}
+	}
+
+	return d, nil
+}
+
+// launch reserves a slot in the selected door and hands the terminal to it.
+func (d *DoorsScreen) launch(idx int) tea.Cmd {
+	specs := d.doors.Doors()
+	if idx < 0 || idx >= len(specs) {
+		return nil
+	}
+	name := specs[idx].Name
+
+	cmd, err := d.doors.Prepare(name, door.DropInfo{
+		Handle: d.username,
+		Node:   d.node,
+		Width:  d.width,
+		Height: d.height,
+	})
+	if err != nil {
+		d.setStatus(fmt.Sprintf("%s: %v", name, err), true)
+		return nil
+	}
+
+	d.status = ""
+	return tea.Exec(cmd, func(err error) tea.Msg {
+		return doorExitMsg{name: name, cmd: cmd, err: err}
+	})
+}
+
+func (d *DoorsScreen) setStatus(s string, failed bool) {
+	d.status = s
+	d.failed = failed
+}
+
+func (d *DoorsScreen) View() string {
+	w := d.width
+	if w > theme.MaxWidth {
+		w = theme.MaxWidth
+	}
+	if w < 40 {
+		w = 40
+	}
+
+	var b strings.Builder
+
+	titleStyle := lipgloss.NewStyle().Foreground(theme.Gold).Bold(true)
+	b.WriteString(titleStyle.Render("DOORS -- EXTERNAL PROGRAMS"))
+	b.WriteString("\n")
+	b.WriteString(components.RenderDivider(w))
+	b.WriteString("\n\n")
+
+	specs := d.doors.Doors()
+	if len(specs) == 0 {
+		b.WriteString(lipgloss.NewStyle().Foreground(theme.Secondary).Render("  No doors are installed."))
+		b.WriteString("\n")
+		return b.String()
+	}
+
+	for i, s := range specs {
+		num := fmt.Sprintf("[%d]", i+1)
+		meta := fmt.Sprintf("%s limit", s.TimeLimit)
+		if s.MaxConcurrent > 0 {
+			meta += fmt.Sprintf(" │ %d/%d in use", d.doors.Running(s.Name), s.MaxConcurrent)
+		}
+
+		if i == d.cursor {
+			cursor := lipgloss.NewStyle().Foreground(theme.Green).Render("▸ ")
+			numStyle := lipgloss.NewStyle().Foreground(theme.GreenBright).Bold(true)
+			nameStyle := lipgloss.NewStyle().Foreground(theme.GreenBright).Bold(true)
+			b.WriteString(cursor + numStyle.Render(num) + " " + nameStyle.Render(s.Name))
+		} else {
+			numStyle := lipgloss.NewStyle().Foreground(theme.Green).Bold(true)
+			nameStyle := lipgloss.NewStyle().Foreground(theme.Text)
+			b.WriteString("  " + numStyle.Render(num) + " " + nameStyle.Render(s.Name))
+		}
+		b.WriteString(" " + lipgloss.NewStyle().Foreground(theme.Muted).Render("─ "+meta))
+		b.WriteString("\n")
+		if s.Description != "" {
+			b.WriteString("      " + lipgloss.NewStyle().Foreground(theme.Secondary).Render(truncate(s.Description, w-8)))
+			b.WriteString("\n")
+		}
+	}
+
+	if d.status != "" {
+		color := theme.Green
+		if d.failed {
+			color = theme.Red
+		}
+		b.WriteString("\n  " + lipgloss.NewStyle().Foreground(color).Render(d.status))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	hintStyle := lipgloss.NewStyle().Foreground(theme.Muted)
+	b.WriteString(hintStyle.Render("  Enter to open door  |  j/k navigate  |  q back"))
+	b.WriteString("\n")
+
+	return b.String()
+}
+
+func (d *DoorsScreen) StatusInfo() (string, *int) {
+	return "DOORS", nil
+}
@@ -66,8 +66,8 @@ func (h *HelpScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 func (h *HelpScreen) contentWidth() int {
 	w := h.width
-	if w > 78 {
-		w = 78
+	if w > theme.MaxWidth {
+		w = theme.MaxWidth
 	}
 	return w
 }
@@ -102,13 +102,22 @@ func (h *HelpScreen) renderContent() {
 	lines = append(lines, formatKey("u", "Half page up"))
 	lines = append(lines, formatKey("g", "Go to top"))
 	lines = append(lines, formatKey("G", "Go to bottom"))
+	lines = append(lines, formatKey("o", "Outline: jump to a section"))
+	lines = append(lines, formatKey("]] / [[", "Next / previous section"))
+	lines = append(lines, formatKey("l", "Follow a link [1], [2], ..."))
+	lines = append(lines, formatKey("c", "Copy a code block"))
+	lines = append(lines, formatKey("y", "Copy the article's URL"))
 	lines = append(lines, formatKey("p", "Previous article"))
 	lines = append(lines, formatKey("n", "Next article"))
+	lines = append(lines, formatKey("/", "Find in article"))
+	lines = append(lines, formatKey("n / N", "Next / previous match"))
+	lines = append(lines, formatKey("Esc", "Clear find"))
+	lines = append(lines, formatKey("F", "Submit a CTF flag"))
 	lines = append(lines, "")
 	lines = append(lines, sectionStyle.Render("GLOBAL"))
 	lines = append(lines, "")
 	lines = append(lines, formatKey("?", "Toggle help"))
-	lines = append(lines, formatKey("/", "Open search"))
+	lines = append(lines, formatKey("/ or s", "Search all articles (s in articles)"))
 	lines = append(lines, formatKey("Ctrl+C", "Quit / disconnect"))
 
 	content := components.RenderBoxFrame("KEYBOARD REFERENCE", lines, w)
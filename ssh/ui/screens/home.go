@@ -5,11 +5,16 @@ import (
 	"strings"
 	"time"
 
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
+	"terminull-ssh/callers"
 	"terminull-ssh/content"
+	"terminull-ssh/door"
+	"terminull-ssh/oneliners"
 	"terminull-ssh/ui/components"
+	"terminull-ssh/ui/layout"
 	"terminull-ssh/ui/theme"
 )
 
@@ -21,6 +26,14 @@ const (
 	phaseDone
 )
 
+const (
+	// wallSize is how many oneliners are shown on the home screen.
+	wallSize = 5
+
+	// lastCallersSize is how many recent calls are shown on the home screen.
+	lastCallersSize = 10
+)
+
 type connectTickMsg struct{}
 
 // HomeScreen shows connection animation then main menu.
@@ -29,21 +42,29 @@ type HomeScreen struct {
 	width    int
 	height   int
 	username string
+	keyID    string
 	siteURL  string
 	phase    int
 	cursor   int
 	items    []menuItem
+
+	calls     *callers.Log
+	wall      *oneliners.Wall
+	composing bool
+	input     textinput.Model
+	wallMsg   string
+	wallErr   bool
 }
 
 type menuItem struct {
 	label       string
 	description string
-	action      string // "volume", "page", "help"
+	action      string // "volume", "page", "polls", "scoreboard", "doors", "callers", "help"
 	volume      int
 	pageSlug    string
 }
 
-func NewHomeScreen(store *content.Store, width, height int, username, siteURL string) *HomeScreen {
+func NewHomeScreen(store *content.Store, doors *door.Registry, wall *oneliners.Wall, calls *callers.Log, width, height int, username, keyID, siteURL string) *HomeScreen {
 	var items []menuItem
 
 	// Add volumes
@@ -66,6 +87,42 @@ func NewHomeScreen(store *content.Store, width, height int, username, siteURL st
 		})
 	}
 
+	// Voting booth
+	if len(store.Polls) > 0 {
+		items = append(items, menuItem{
+			label:       "Voting Booth",
+			description: fmt.Sprintf("%d polls", len(store.Polls)),
+			action:      "polls",
+		})
+	}
+
+	// CTF scoreboard
+	if n := len(store.Challenges()); n > 0 {
+		items = append(items, menuItem{
+			label:       "CTF Scoreboard",
+			description: fmt.Sprintf("%d challenges", n),
+			action:      "scoreboard",
+		})
+	}
+
+	// Doors
+	if doors.Len() > 0 {
+		items = append(items, menuItem{
+			label:       "Doors — External Programs",
+			description: fmt.Sprintf("%d doors", doors.Len()),
+			action:      "doors",
+		})
+	}
+
+	// Last callers
+	if calls != nil {
+		items = append(items, menuItem{
+			label:       "Last Callers",
+			description: "Recent sessions on this board",
+			action:      "callers",
+		})
+	}
+
 	// Help
 	items = append(items, menuItem{
 		label:       "Help — Keyboard Reference",
@@ -73,14 +130,25 @@ func NewHomeScreen(store *content.Store, width, height int, username, siteURL st
 		action:      "help",
 	})
 
+	ti := textinput.New()
+	ti.Placeholder = "say something..."
+	ti.CharLimit = oneliners.MaxLen
+	ti.TextStyle = lipgloss.NewStyle().Foreground(theme.Text)
+	ti.PromptStyle = lipgloss.NewStyle().Foreground(theme.Green)
+	ti.Prompt = "> "
+
 	return &HomeScreen{
 		store:    store,
 		width:    width,
 		height:   height,
 		username: username,
+		keyID:    keyID,
 		siteURL:  siteURL,
 		phase:    phaseConnecting,
 		items:    items,
+		calls:    calls,
+		wall:     wall,
+		input:    ti,
 	}
 }
 
@@ -113,6 +181,10 @@ func (h *HomeScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return h, nil
 		}
 
+		if h.composing {
+			return h, h.updateCompose(msg)
+		}
+
 		switch msg.String() {
 		case "j", "down":
 			if h.cursor < len(h.items)-1 {
@@ -140,9 +212,17 @@ func (h *HomeScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return h, h.selectItem(idx)
 			}
 			return h, nil
+		case "w":
+			if h.wall == nil {
+				return h, nil
+			}
+			h.composing = true
+			h.wallMsg = ""
+			h.input.Reset()
+			return h, h.input.Focus()
 		case "?":
 			return h, navigateCmd("help", 0, 0, "", "")
-		case "/":
+		case "/", "s":
 			return h, navigateCmd("search", 0, 0, "", "")
 		case "q":
 			return h, func() tea.Msg { return tea.QuitMsg{} }
@@ -152,6 +232,29 @@ func (h *HomeScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return h, nil
 }
 
+// updateCompose handles keys while the caller is writing a oneliner.
+func (h *HomeScreen) updateCompose(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		h.composing = false
+		h.input.Blur()
+		return nil
+	case "enter":
+		h.composing = false
+		h.input.Blur()
+		if err := h.wall.Post(h.username, h.keyID, h.input.Value()); err != nil {
+			h.wallMsg, h.wallErr = err.Error(), true
+		} else {
+			h.wallMsg, h.wallErr = "Your oneliner is on the wall.", false
+		}
+		return nil
+	}
+
+	var cmd tea.Cmd
+	h.input, cmd = h.input.Update(msg)
+	return cmd
+}
+
 func (h *HomeScreen) selectItem(idx int) tea.Cmd {
 	if idx < 0 || idx >= len(h.items) {
 		return nil
@@ -162,17 +265,36 @@ func (h *HomeScreen) selectItem(idx int) tea.Cmd {
 		return navigateCmd("volume", item.volume, 0, "", "")
 	case "page":
 		return navigateCmd("page", 0, 0, item.pageSlug, "")
+	case "polls":
+		return navigateCmd("polls", 0, 0, "", "")
+	case "scoreboard":
+		return navigateCmd("scoreboard", 0, 0, "", "")
+	case "doors":
+		return navigateCmd("doors", 0, 0, "", "")
+	case "callers":
+		return navigateCmd("callers", 0, 0, "", "")
 	case "help":
 		return navigateCmd("help", 0, 0, "", "")
 	}
 	return nil
 }
 
+// Home screen sections, in the order they appear.
+const (
+	secConnect = iota
+	secLogo
+	secInfo
+	secMenu
+	secMOTD
+	secWall
+	secCallers
+	secFooter
+	numSections
+)
+
 func (h *HomeScreen) View() string {
-	w := h.width
-	if w > 78 {
-		w = 78
-	}
+	l := layout.For(h.width, h.height)
+	w := l.Content
 
 	var b strings.Builder
 
@@ -197,67 +319,139 @@ func (h *HomeScreen) View() string {
 		return b.String()
 	}
 
-	b.WriteString("\n")
+	var sec [numSections]string
+	sec[secConnect] = strings.TrimSuffix(b.String(), "\n")
 
-	// Logo
-	b.WriteString(components.RenderLogo(w))
-	b.WriteString("\n")
-
-	// Tagline
+	// Logo and tagline
 	latestVol := 0
 	if len(h.store.Volumes) > 0 {
 		latestVol = h.store.Volumes[len(h.store.Volumes)-1].Number
 	}
-	b.WriteString(components.RenderTagline(latestVol, w))
-	b.WriteString("\n\n")
+	logo := components.RenderLogo(w)
+	if l.Mode == layout.Compact {
+		logo = components.RenderWordmark()
+	}
+	sec[secLogo] = logo + "\n" + components.RenderTagline(latestVol, w)
+
+	sec[secInfo] = components.RenderSystemInfo(h.username, w)
+	sec[secMOTD] = components.RenderMOTD(w)
+
+	// Oneliner wall
+	if h.wall != nil {
+		hintStyle := lipgloss.NewStyle().Foreground(theme.Muted)
+		var hint string
+		switch {
+		case h.composing:
+			hint = " " + h.input.View() + "  " + hintStyle.Render("Enter to post | Esc to cancel")
+		case h.wallMsg != "":
+			color := theme.Green
+			if h.wallErr {
+				color = theme.Red
+			}
+			hint = "  " + lipgloss.NewStyle().Foreground(color).Render(h.wallMsg)
+		default:
+			hint = hintStyle.Render("  [w] write on the wall")
+		}
+		sec[secWall] = components.RenderOneliners(h.wall.Recent(wallSize), w) + "\n" + hint
+	}
+
+	// Last callers
+	if h.calls != nil {
+		sec[secCallers] = components.RenderLastCallers(h.calls.Recent(lastCallersSize), w)
+	}
+
+	sec[secFooter] = components.RenderFooter(w)
+
+	// The menu always shows. The other sections fill whatever room is
+	// left, most important first; a compact screen keeps to the essentials.
+	order := []int{secLogo, secWall, secInfo, secMOTD, secCallers, secFooter, secConnect}
+	if l.Mode == layout.Compact {
+		order = []int{secLogo, secWall, secCallers}
+	}
+	if h.composing || h.wallMsg != "" {
+		// The caller is using the wall, so it must not be left out.
+		order = append([]int{secWall}, order...)
+	}
+
+	rows := h.height - 1 // the status bar's line
+	sec[secMenu] = h.renderMenu(w, rows, l.Mode == layout.Compact)
+	room := rows - lines(sec[secMenu])
+	show := [numSections]bool{secMenu: true}
+	for _, s := range order {
+		if sec[s] == "" || show[s] {
+			continue
+		}
+		if n := lines(sec[s]) + 1; n <= room {
+			show[s] = true
+			room -= n
+		}
+	}
 
-	// System info
-	b.WriteString(components.RenderSystemInfo(h.username, w))
-	b.WriteString("\n\n")
+	var parts []string
+	for s := range numSections {
+		if show[s] {
+			parts = append(parts, sec[s])
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// renderMenu renders the main menu in at most rows lines, scrolling the
+// items to keep the cursor in view. Compact menus leave out descriptions.
+func (h *HomeScreen) renderMenu(w, rows int, compact bool) string {
+	var b strings.Builder
 
-	// Menu
 	titleStyle := lipgloss.NewStyle().Foreground(theme.Gold).Bold(true)
 	b.WriteString(titleStyle.Render("MAIN MENU"))
 	b.WriteString("\n")
-	b.WriteString(components.RenderDivider(w))
-	b.WriteString("\n\n")
+	head := 1
+	if !compact {
+		b.WriteString(components.RenderDivider(w))
+		b.WriteString("\n\n")
+		head = 3
+	}
+
+	first, last := layout.Window(len(h.items), h.cursor, rows-head)
 
-	for i, item := range h.items {
+	for i := first; i < last; i++ {
+		item := h.items[i]
 		num := fmt.Sprintf("[%d]", i+1)
+		// Room for the cursor, the number and a space.
+		label := components.Truncate(item.label, w-3-len(num))
+		desc := item.description
+		if compact || lipgloss.Width(item.label+desc)+len(num)+6 > w {
+			desc = ""
+		}
 		if i == h.cursor {
 			numStyle := lipgloss.NewStyle().Foreground(theme.GreenBright).Bold(true)
 			labelStyle := lipgloss.NewStyle().Foreground(theme.GreenBright).Bold(true)
 			descStyle := lipgloss.NewStyle().Foreground(theme.Green)
 			cursor := lipgloss.NewStyle().Foreground(theme.Green).Render("▸ ")
-			b.WriteString(cursor + numStyle.Render(num) + " " + labelStyle.Render(item.label))
-			if item.description != "" {
-				b.WriteString(" " + lipgloss.NewStyle().Foreground(theme.Muted).Render("─") + " " + descStyle.Render(item.description))
+			b.WriteString(cursor + numStyle.Render(num) + " " + labelStyle.Render(label))
+			if desc != "" {
+				b.WriteString(" " + lipgloss.NewStyle().Foreground(theme.Muted).Render("─") + " " + descStyle.Render(desc))
 			}
 		} else {
 			numStyle := lipgloss.NewStyle().Foreground(theme.Green).Bold(true)
 			labelStyle := lipgloss.NewStyle().Foreground(theme.Text)
 			descStyle := lipgloss.NewStyle().Foreground(theme.Secondary)
-			b.WriteString("  " + numStyle.Render(num) + " " + labelStyle.Render(item.label))
-			if item.description != "" {
-				b.WriteString(" " + lipgloss.NewStyle().Foreground(theme.Muted).Render("─") + " " + descStyle.Render(item.description))
+			b.WriteString("  " + numStyle.Render(num) + " " + labelStyle.Render(label))
+			if desc != "" {
+				b.WriteString(" " + lipgloss.NewStyle().Foreground(theme.Muted).Render("─") + " " + descStyle.Render(desc))
 			}
 		}
-		b.WriteString("\n")
+		if i < last-1 {
+			b.WriteString("\n")
+		}
 	}
-
-	b.WriteString("\n")
-
-	// MOTD
-	b.WriteString(components.RenderMOTD(w))
-	b.WriteString("\n\n")
-
-	// Footer
-	b.WriteString(components.RenderFooter(w))
-	b.WriteString("\n")
-
 	return b.String()
 }
 
+// lines counts the lines in s.
+func lines(s string) int {
+	return strings.Count(s, "\n") + 1
+}
+
 func (h *HomeScreen) StatusInfo() (string, *int) {
 	return "HOME", nil
 }
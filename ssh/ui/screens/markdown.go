@@ -0,0 +1,75 @@
+package screens
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"terminull-ssh/content"
+	"terminull-ssh/metrics"
+	"terminull-ssh/render"
+	"terminull-ssh/ui/theme"
+)
+
+// renderMarkdown renders md, the document key, with Glamour, wrapped to
+// key.Width, within budget. Markdown over budget, or that Glamour cannot
+// render, comes back as plain wrapped text along with the reason.
+func renderMarkdown(kind string, key render.Key, md string, budget render.Budget) (string, error) {
+	text, err := budget.Run(key, func() (string, error) {
+		defer metrics.ObserveRender(kind, time.Now())
+		return glamourMarkdown(md, key.Width)
+	})
+	if err != nil {
+		return render.Plain(md, key.Width), err
+	}
+	return text, nil
+}
+
+// glamourMarkdown renders md with Glamour, wrapped to width.
+func glamourMarkdown(md string, width int) (string, error) {
+	renderer, err := theme.NewGlamourRenderer(width)
+	if err != nil {
+		return "", err
+	}
+	return renderer.Render(md)
+}
+
+// CheckRenderBudget returns a content check that renders every article and
+// page at the usual width and warns of any over budget, since callers see
+// those as plain text, and of any it could not check because too many
+// renderings over time were still running. These renderings are left out of the render
+// latency metrics, which are for what callers wait on.
+func CheckRenderBudget(siteURL string, budget render.Budget) content.Check {
+	return func(store *content.Store) []string {
+		var warnings []string
+		check := func(name, slug, md string) {
+			key := markdownKey(slug, md, theme.MaxWidth-2)
+			_, err := budget.Run(key, func() (string, error) { return glamourMarkdown(md, key.Width) })
+			switch {
+			case errors.Is(err, render.ErrOverBudget):
+				warnings = append(warnings, fmt.Sprintf("%s shown as plain text, %v", name, err))
+			case errors.Is(err, render.ErrBusy):
+				warnings = append(warnings, fmt.Sprintf("%s not checked, %v", name, err))
+			}
+		}
+		for _, v := range store.Volumes {
+			for i := range v.Articles {
+				a := &v.Articles[i]
+				// As ArticleScreen renders it.
+				md, _ := content.NumberLinks(content.PreprocessMarkdown(a.Body, siteURL, v.Number, a.Slug))
+				slug := fmt.Sprintf("vol%d/%s", v.Number, a.Slug)
+				check(slug, slug, md)
+			}
+		}
+		for _, p := range store.Pages {
+			check("page "+p.Slug, "page/"+p.Slug, p.Body)
+		}
+		return warnings
+	}
+}
+
+// markdownKey returns the render.Key of md, the document slug, rendered
+// at width in the current style.
+func markdownKey(slug, md string, width int) render.Key {
+	return render.Key{Slug: slug, Hash: render.Hash(md), Width: width, Theme: theme.GlamourTheme}
+}
@@ -35,3 +35,10 @@ func replaceCmd(screen string, volume, article int) tea.Cmd {
 		}
 	}
 }
+
+// clipboardCmd returns a command that emits a ClipboardMsg.
+func clipboardCmd(text string) tea.Cmd {
+	return func() tea.Msg {
+		return types.ClipboardMsg{Text: text}
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/charmbracelet/lipgloss"
 
 	"terminull-ssh/content"
+	"terminull-ssh/render"
 	"terminull-ssh/ui/theme"
 )
 
@@ -18,10 +19,11 @@ type PageScreen struct {
 	viewport viewport.Model
 	width    int
 	height   int
+	budget   render.Budget
 	ready    bool
 }
 
-func NewPageScreen(store *content.Store, slug string, width, height int) *PageScreen {
+func NewPageScreen(store *content.Store, slug string, width, height int, budget render.Budget) *PageScreen {
 	var page *content.Page
 	for i := range store.Pages {
 		if store.Pages[i].Slug == slug {
@@ -35,6 +37,7 @@ func NewPageScreen(store *content.Store, slug string, width, height int) *PageSc
 		page:   page,
 		width:  width,
 		height: height,
+		budget: budget,
 	}
 	s.initViewport()
 	return s
@@ -73,7 +76,7 @@ func (p *PageScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return p, backCmd()
 		case "?":
 			return p, navigateCmd("help", 0, 0, "", "")
-		case "/":
+		case "/", "s":
 			return p, navigateCmd("search", 0, 0, "", "")
 		case "g":
 			p.viewport.GotoTop()
@@ -91,8 +94,8 @@ func (p *PageScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 func (p *PageScreen) contentWidth() int {
 	w := p.width
-	if w > 78 {
-		w = 78
+	if w > theme.MaxWidth {
+		w = theme.MaxWidth
 	}
 	return w
 }
@@ -116,17 +119,9 @@ func (p *PageScreen) renderContent() {
 	b.WriteString("\n\n")
 
 	// Render markdown body
-	renderer, err := theme.NewGlamourRenderer(w - 2)
-	if err == nil {
-		rendered, err := renderer.Render(p.page.Body)
-		if err == nil {
-			b.WriteString(rendered)
-		} else {
-			b.WriteString(p.page.Body)
-		}
-	} else {
-		b.WriteString(p.page.Body)
-	}
+	key := markdownKey("page/"+p.page.Slug, p.page.Body, w-2)
+	body, _ := renderMarkdown("page", key, p.page.Body, p.budget)
+	b.WriteString(body)
 
 	p.viewport.SetContent(b.String())
 }
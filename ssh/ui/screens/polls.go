@@ -0,0 +1,288 @@
+package screens
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"terminull-ssh/content"
+	"terminull-ssh/ui/components"
+	"terminull-ssh/ui/theme"
+	"terminull-ssh/votes"
+)
+
+// PollsScreen is the voting booth: a list of polls, and for the selected
+// poll either its ballot or its results.
+type PollsScreen struct {
+	store  *content.Store
+	booth  *votes.Booth
+	keyID  string
+	width  int
+	height int
+	cursor int
+	poll   *content.Poll // nil while browsing the list
+	choice int
+	status string
+	failed bool
+}
+
+func NewPollsScreen(store *content.Store, booth *votes.Booth, keyID string, width, height int) *PollsScreen {
+	return &PollsScreen{
+		store:  store,
+		booth:  booth,
+		keyID:  keyID,
+		width:  width,
+		height: height,
+	}
+}
+
+func (p *PollsScreen) Init() tea.Cmd { return nil }
+
+func (p *PollsScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		p.width = msg.Width
+		p.height = msg.Height
+		return p, nil
+
+	case tea.KeyMsg:
+		if p.poll != nil {
+			return p, p.updateBallot(msg)
+		}
+
+		switch msg.String() {
+		case "j", "down":
+			if p.cursor < len(p.store.Polls)-1 {
+				p.cursor++
+			}
+			return p, nil
+		case "k", "up":
+			if p.cursor > 0 {
+				p.cursor--
+			}
+			return p, nil
+		case "enter":
+			p.open(p.cursor)
+			return p, nil
+		case "1", "2", "3", "4", "5", "6", "7", "8", "9":
+			idx := int(msg.String()[0]-'0') - 1
+			if idx < len(p.store.Polls) {
+				p.cursor = idx
+				p.open(idx)
+			}
+			return p, nil
+		case "q", "esc":
+			return p, backCmd()
+		case "?":
+			return p, navigateCmd("help", 0, 0, "", "")
+		}
+	}
+
+	return p, nil
+}
+
+// updateBallot handles keys while a single poll is open.
+func (p *PollsScreen) updateBallot(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "j", "down":
+		if p.choice < len(p.poll.Options)-1 {
+			p.choice++
+		}
+	case "k", "up":
+		if p.choice > 0 {
+			p.choice--
+		}
+	case "enter":
+		if p.showResults() {
+			return nil
+		}
+		if err := p.booth.Vote(p.poll.ID, p.keyID, p.poll.Options, p.choice); err != nil {
+			p.status, p.failed = err.Error(), true
+		} else {
+			p.status, p.failed = "Vote recorded. Thanks!", false
+		}
+	case "q", "esc":
+		p.poll = nil
+		p.status = ""
+	}
+	return nil
+}
+
+func (p *PollsScreen) open(idx int) {
+	if idx < 0 || idx >= len(p.store.Polls) {
+		return
+	}
+	p.poll = &p.store.Polls[idx]
+	p.choice = 0
+	p.status = ""
+}
+
+// showResults reports whether the open poll's results may be shown: after
+// the caller has voted, or once the poll has closed.
+func (p *PollsScreen) showResults() bool {
+	return p.poll.Closed(time.Now()) || p.booth.HasVoted(p.poll.ID, p.keyID)
+}
+
+func (p *PollsScreen) View() string {
+	w := p.width
+	if w > theme.MaxWidth {
+		w = theme.MaxWidth
+	}
+
+	var b strings.Builder
+
+	titleStyle := lipgloss.NewStyle().Foreground(theme.Gold).Bold(true)
+	b.WriteString(titleStyle.Render("VOTING BOOTH"))
+	b.WriteString("\n")
+	b.WriteString(components.RenderDivider(w))
+	b.WriteString("\n\n")
+
+	if p.poll != nil {
+		b.WriteString(p.viewPoll(w))
+		return b.String()
+	}
+
+	if len(p.store.Polls) == 0 {
+		b.WriteString(lipgloss.NewStyle().Foreground(theme.Secondary).Render("  No polls are running."))
+		b.WriteString("\n")
+		return b.String()
+	}
+
+	now := time.Now()
+	for i, poll := range p.store.Polls {
+		num := fmt.Sprintf("[%d]", i+1)
+		state := "open"
+		switch {
+		case poll.Closed(now):
+			state = "closed"
+		case p.booth.HasVoted(poll.ID, p.keyID):
+			state = "voted"
+		case !poll.Closes.IsZero():
+			state = "open until " + poll.Closes.Format("2006-01-02")
+		}
+
+		if i == p.cursor {
+			cursor := lipgloss.NewStyle().Foreground(theme.Green).Render("▸ ")
+			numStyle := lipgloss.NewStyle().Foreground(theme.GreenBright).Bold(true)
+			qStyle := lipgloss.NewStyle().Foreground(theme.GreenBright).Bold(true)
+			b.WriteString(cursor + numStyle.Render(num) + " " + qStyle.Render(truncate(poll.Question, w-8)))
+		} else {
+			numStyle := lipgloss.NewStyle().Foreground(theme.Green).Bold(true)
+			qStyle := lipgloss.NewStyle().Foreground(theme.Text)
+			b.WriteString("  " + numStyle.Render(num) + " " + qStyle.Render(truncate(poll.Question, w-8)))
+		}
+		b.WriteString("\n")
+		b.WriteString("      " + lipgloss.NewStyle().Foreground(theme.Secondary).Render(state))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	hintStyle := lipgloss.NewStyle().Foreground(theme.Muted)
+	b.WriteString(hintStyle.Render("  Enter to open  |  j/k navigate  |  q back"))
+	b.WriteString("\n")
+
+	return b.String()
+}
+
+// viewPoll renders the ballot, or the results once they may be shown.
+func (p *PollsScreen) viewPoll(w int) string {
+	var b strings.Builder
+
+	qStyle := lipgloss.NewStyle().Foreground(theme.Cyan).Bold(true)
+	b.WriteString(qStyle.Render(p.poll.Question))
+	b.WriteString("\n")
+	if p.poll.Description != "" {
+		b.WriteString(lipgloss.NewStyle().Foreground(theme.Secondary).Render(p.poll.Description))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	results := p.showResults()
+	if results {
+		b.WriteString(renderResults(p.poll.Options, p.booth.Tally(p.poll.ID, p.poll.Options), w))
+	} else {
+		for i, opt := range p.poll.Options {
+			if i == p.choice {
+				cursor := lipgloss.NewStyle().Foreground(theme.Green).Render("▸ ")
+				b.WriteString(cursor + lipgloss.NewStyle().Foreground(theme.GreenBright).Bold(true).Render("( ) "+opt))
+			} else {
+				b.WriteString("  " + lipgloss.NewStyle().Foreground(theme.Text).Render("( ) "+opt))
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	if p.status != "" {
+		color := theme.Green
+		if p.failed {
+			color = theme.Red
+		}
+		b.WriteString("\n  " + lipgloss.NewStyle().Foreground(color).Render(p.status))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	hint := "  Enter to vote  |  j/k choose  |  q back to polls"
+	if results {
+		hint = "  q back to polls"
+	}
+	b.WriteString(lipgloss.NewStyle().Foreground(theme.Muted).Render(hint))
+	b.WriteString("\n")
+
+	return b.String()
+}
+
+// renderResults draws one horizontal bar per option, scaled to the leader.
+func renderResults(options []string, tally []int, w int) string {
+	total, most := 0, 0
+	labelWidth := 0
+	for i, n := range tally {
+		total += n
+		if n > most {
+			most = n
+		}
+		if l := lipgloss.Width(options[i]); l > labelWidth {
+			labelWidth = l
+		}
+	}
+	if labelWidth > 28 {
+		labelWidth = 28
+	}
+	barWidth := w - labelWidth - 18
+	if barWidth < 5 {
+		barWidth = 5
+	}
+
+	labelStyle := lipgloss.NewStyle().Foreground(theme.Text).Width(labelWidth).MaxWidth(labelWidth)
+	barStyle := lipgloss.NewStyle().Foreground(theme.Green)
+	emptyStyle := lipgloss.NewStyle().Foreground(theme.Muted)
+	countStyle := lipgloss.NewStyle().Foreground(theme.Secondary)
+
+	var b strings.Builder
+	for i, opt := range options {
+		filled := 0
+		if most > 0 {
+			filled = tally[i] * barWidth / most
+		}
+		pct := 0
+		if total > 0 {
+			pct = tally[i] * 100 / total
+		}
+		b.WriteString("  " + labelStyle.Render(opt) + " " +
+			barStyle.Render(strings.Repeat("█", filled)) +
+			emptyStyle.Render(strings.Repeat("░", barWidth-filled)) +
+			countStyle.Render(fmt.Sprintf(" %4d %3d%%", tally[i], pct)))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+	b.WriteString(countStyle.Render(fmt.Sprintf("  %d vote(s) total", total)))
+	b.WriteString("\n")
+	return b.String()
+}
+
+func (p *PollsScreen) StatusInfo() (string, *int) {
+	return "POLLS", nil
+}
@@ -0,0 +1,136 @@
+package screens
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"terminull-ssh/content"
+	"terminull-ssh/ctf"
+	"terminull-ssh/ui/components"
+	"terminull-ssh/ui/theme"
+)
+
+// ScoreboardScreen ranks handles by CTF points.
+type ScoreboardScreen struct {
+	store  *content.Store
+	scores *ctf.Scoreboard
+	width  int
+	height int
+	offset int
+}
+
+func NewScoreboardScreen(store *content.Store, scores *ctf.Scoreboard, width, height int) *ScoreboardScreen {
+	return &ScoreboardScreen{
+		store:  store,
+		scores: scores,
+		width:  width,
+		height: height,
+	}
+}
+
+func (s *ScoreboardScreen) Init() tea.Cmd { return nil }
+
+func (s *ScoreboardScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		s.width = msg.Width
+		s.height = msg.Height
+		return s, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "j", "down":
+			if s.offset < len(s.scores.Standings(s.store.Challenges()))-s.pageSize() {
+				s.offset++
+			}
+			return s, nil
+		case "k", "up":
+			if s.offset > 0 {
+				s.offset--
+			}
+			return s, nil
+		case "q", "esc":
+			return s, backCmd()
+		case "?":
+			return s, navigateCmd("help", 0, 0, "", "")
+		}
+	}
+
+	return s, nil
+}
+
+// pageSize is how many rows fit below the header and above the hints.
+func (s *ScoreboardScreen) pageSize() int {
+	n := s.height - 10
+	if n < 3 {
+		n = 3
+	}
+	return n
+}
+
+func (s *ScoreboardScreen) View() string {
+	w := s.width
+	if w > theme.MaxWidth {
+		w = theme.MaxWidth
+	}
+
+	var b strings.Builder
+
+	challenges := s.store.Challenges()
+	total := 0
+	for _, c := range challenges {
+		total += c.Points
+	}
+
+	titleStyle := lipgloss.NewStyle().Foreground(theme.Gold).Bold(true)
+	b.WriteString(titleStyle.Render("SCOREBOARD"))
+	b.WriteString("\n")
+	b.WriteString(components.RenderDivider(w))
+	b.WriteString("\n")
+	b.WriteString(lipgloss.NewStyle().Foreground(theme.Secondary).Render(
+		fmt.Sprintf("  %d challenges // %d points up for grabs", len(challenges), total)))
+	b.WriteString("\n\n")
+
+	standings := s.scores.Standings(challenges)
+	if len(standings) == 0 {
+		b.WriteString(lipgloss.NewStyle().Foreground(theme.Secondary).Render("  No solves yet. Find a challenge in an article and press f."))
+		b.WriteString("\n")
+	} else {
+		headerStyle := lipgloss.NewStyle().Foreground(theme.Muted)
+		b.WriteString(headerStyle.Render(fmt.Sprintf("  %-6s%-24s%-10s%s", "RANK", "HANDLE", "POINTS", "SOLVES")))
+		b.WriteString("\n")
+		b.WriteString(headerStyle.Render("  " + strings.Repeat("─", 46)))
+		b.WriteString("\n")
+
+		end := s.offset + s.pageSize()
+		if end > len(standings) {
+			end = len(standings)
+		}
+		for i := s.offset; i < end; i++ {
+			st := standings[i]
+			rankStyle := lipgloss.NewStyle().Foreground(theme.Green)
+			if i < 3 {
+				rankStyle = lipgloss.NewStyle().Foreground(theme.Gold).Bold(true)
+			}
+			b.WriteString("  " + rankStyle.Render(fmt.Sprintf("%-6s", fmt.Sprintf("#%d", i+1))) +
+				lipgloss.NewStyle().Foreground(theme.Text).Render(fmt.Sprintf("%-24s", truncate(st.Handle, 23))) +
+				lipgloss.NewStyle().Foreground(theme.GreenBright).Render(fmt.Sprintf("%-10d", st.Points)) +
+				lipgloss.NewStyle().Foreground(theme.Secondary).Render(fmt.Sprintf("%d/%d", st.Solves, len(challenges))))
+			b.WriteString("\n")
+		}
+	}
+
+	b.WriteString("\n")
+	hintStyle := lipgloss.NewStyle().Foreground(theme.Muted)
+	b.WriteString(hintStyle.Render("  j/k scroll  |  q back"))
+	b.WriteString("\n")
+
+	return b.String()
+}
+
+func (s *ScoreboardScreen) StatusInfo() (string, *int) {
+	return "SCOREBOARD", nil
+}
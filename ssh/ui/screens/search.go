@@ -128,8 +128,8 @@ func (s *SearchScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 func (s *SearchScreen) View() string {
 	w := s.width
-	if w > 78 {
-		w = 78
+	if w > theme.MaxWidth {
+		w = theme.MaxWidth
 	}
 
 	var b strings.Builder
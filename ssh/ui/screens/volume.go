@@ -9,6 +9,7 @@ import (
 
 	"terminull-ssh/content"
 	"terminull-ssh/ui/components"
+	"terminull-ssh/ui/layout"
 	"terminull-ssh/ui/theme"
 )
 
@@ -88,7 +89,7 @@ func (v *VolumeScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return v, backCmd()
 		case "?":
 			return v, navigateCmd("help", 0, 0, "", "")
-		case "/":
+		case "/", "s":
 			return v, navigateCmd("search", 0, 0, "", "")
 		}
 	}
@@ -97,10 +98,9 @@ func (v *VolumeScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (v *VolumeScreen) View() string {
-	w := v.width
-	if w > 78 {
-		w = 78
-	}
+	l := layout.For(v.width, v.height)
+	w := l.Content
+	compact := l.Mode == layout.Compact
 
 	var b strings.Builder
 
@@ -117,19 +117,32 @@ func (v *VolumeScreen) View() string {
 		return b.String()
 	}
 
-	// Table header
+	// Table header. A compact table has only the number and title columns.
 	headerStyle := lipgloss.NewStyle().Foreground(theme.Muted)
-	b.WriteString(headerStyle.Render(fmt.Sprintf("  %-4s%-38s%-20s%s", "#", "TITLE", "AUTHOR", "CATEGORY")))
-	b.WriteString("\n")
-	b.WriteString(headerStyle.Render("  " + strings.Repeat("─", 4) + strings.Repeat("─", 38) + strings.Repeat("─", 20) + strings.Repeat("─", 14)))
+	if compact {
+		b.WriteString(headerStyle.Render(fmt.Sprintf("  %-4s%s", "#", "TITLE")))
+		b.WriteString("\n")
+		b.WriteString(headerStyle.Render("  " + strings.Repeat("─", w-2)))
+	} else {
+		b.WriteString(headerStyle.Render(fmt.Sprintf("  %-4s%-38s%-20s%s", "#", "TITLE", "AUTHOR", "CATEGORY")))
+		b.WriteString("\n")
+		b.WriteString(headerStyle.Render("  " + strings.Repeat("─", 4) + strings.Repeat("─", 38) + strings.Repeat("─", 20) + strings.Repeat("─", 14)))
+	}
 	b.WriteString("\n")
 
-	// Article rows
-	for i, article := range v.volume.Articles {
+	// Article rows, scrolled to keep the cursor in view. The rest of the
+	// screen takes eight lines, counting the status bar.
+	first, last := layout.Window(len(v.volume.Articles), v.cursor, v.height-8)
+	for i := first; i < last; i++ {
+		article := v.volume.Articles[i]
 		num := fmt.Sprintf("%02d", article.Order)
 		title := truncate(article.Title, 36)
 		author := truncate(article.Author, 18)
 		cat := article.Category
+		if compact {
+			title = components.Truncate(article.Title, w-6)
+			author, cat = "", ""
+		}
 
 		catColor := theme.CategoryColor(cat)
 
@@ -143,8 +156,8 @@ func (v *VolumeScreen) View() string {
 
 			b.WriteString(cursor +
 				numStyle.Render(fmt.Sprintf("%-4s", num)) +
-				titleStyle.Render(fmt.Sprintf("%-38s", title)) +
-				authorStyle.Render(fmt.Sprintf("%-20s", author)) +
+				titleStyle.Render(v.column(title, 38, compact)) +
+				authorStyle.Render(v.column(author, 20, compact)) +
 				catStyle.Render(cat))
 		} else {
 			numStyle := lipgloss.NewStyle().Foreground(theme.Green)
@@ -154,8 +167,8 @@ func (v *VolumeScreen) View() string {
 
 			b.WriteString("  " +
 				numStyle.Render(fmt.Sprintf("%-4s", num)) +
-				titleStyle.Render(fmt.Sprintf("%-38s", title)) +
-				authorStyle.Render(fmt.Sprintf("%-20s", author)) +
+				titleStyle.Render(v.column(title, 38, compact)) +
+				authorStyle.Render(v.column(author, 20, compact)) +
 				catStyle.Render(cat))
 		}
 		b.WriteString("\n")
@@ -163,12 +176,47 @@ func (v *VolumeScreen) View() string {
 
 	b.WriteString("\n")
 	hintStyle := lipgloss.NewStyle().Foreground(theme.Muted)
-	b.WriteString(hintStyle.Render("  Enter to read  |  j/k navigate  |  q back"))
+	if compact {
+		b.WriteString(hintStyle.Render("  Enter read | j/k | q back"))
+	} else {
+		b.WriteString(hintStyle.Render("  Enter to read  |  j/k navigate  |  q back"))
+	}
 	b.WriteString("\n")
 
+	if l.Mode == layout.Wide && v.cursor < len(v.volume.Articles) {
+		preview := v.renderPreview(&v.volume.Articles[v.cursor], min(l.Width-w-l.Gutter(), theme.MaxWidth))
+		return lipgloss.JoinHorizontal(lipgloss.Top,
+			lipgloss.NewStyle().Width(w).Render(b.String()), strings.Repeat(" ", l.Gutter()), preview)
+	}
 	return b.String()
 }
 
+// column pads s to a fixed-width table column; compact tables run their
+// columns together.
+func (v *VolumeScreen) column(s string, width int, compact bool) string {
+	if compact {
+		return s
+	}
+	return fmt.Sprintf("%-*s", width, s)
+}
+
+// renderPreview describes the selected article beside the table in the
+// wide layout.
+func (v *VolumeScreen) renderPreview(a *content.Article, w int) string {
+	lines := []string{
+		fmt.Sprintf("Article #%02d  |  %s", a.Order, strings.ToUpper(a.Category)),
+		"Author: " + a.Author,
+		"Date: " + a.Date.Format("2006-01-02"),
+	}
+	if len(a.Tags) > 0 {
+		lines = append(lines, "Tags: "+strings.Join(a.Tags, ", "))
+	}
+	if a.Description != "" {
+		lines = append(lines, "", a.Description)
+	}
+	return components.RenderBoxFrame(a.Title, lines, w)
+}
+
 func (v *VolumeScreen) StatusInfo() (string, *int) {
 	vol := v.volNum
 	return "TOC", &vol
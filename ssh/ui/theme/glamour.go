@@ -193,6 +193,10 @@ func TerminullStyle() ansi.StyleConfig {
 	}
 }
 
+// GlamourTheme names the style NewGlamourRenderer renders in, to tell
+// cached renderings apart from those in any other style.
+const GlamourTheme = "terminull"
+
 // NewGlamourRenderer creates a Glamour renderer with the terminull theme.
 func NewGlamourRenderer(width int) (*glamour.TermRenderer, error) {
 	style := TerminullStyle()
@@ -61,3 +61,7 @@ var (
 				Background(BgSurface).
 				Bold(true)
 )
+
+// MaxWidth caps how many columns content is laid out in, however wide
+// the caller's terminal is.
+var MaxWidth = 78
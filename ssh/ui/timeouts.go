@@ -0,0 +1,87 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"terminull-ssh/ui/components"
+)
+
+// timeoutWarning is how long before a timeout the caller is warned.
+const timeoutWarning = time.Minute
+
+// Timeouts are the session limits. The app enforces them itself so the
+// caller sees a countdown and a goodbye rather than a dropped line.
+type Timeouts struct {
+	Idle time.Duration // hang up after this long without a keypress; 0 disables
+	Max  time.Duration // hang up this long after connecting; 0 disables
+}
+
+// timeoutTickMsg checks the timeouts once a second. at is when the tick
+// fired.
+type timeoutTickMsg struct{ at time.Time }
+
+func timeoutTick() tea.Cmd {
+	return tea.Tick(time.Second, func(t time.Time) tea.Msg { return timeoutTickMsg{at: t} })
+}
+
+// checkTimeouts hangs up on a caller who has run out of time.
+func (a *AppModel) checkTimeouts(msg timeoutTickMsg) tea.Cmd {
+	if a.goodbye != "" {
+		return nil
+	}
+	// A tick that waited to be handled means the program was paused, which
+	// only happens while a door has the terminal. The caller was busy in
+	// there, not idle.
+	if time.Since(msg.at) > 2*time.Second {
+		a.lastKey = time.Now()
+	}
+
+	switch {
+	case a.idleLeft() == 0:
+		return a.hangUp("Connection closed due to inactivity. Call again any time.")
+	case a.maxLeft() == 0:
+		return a.hangUp(fmt.Sprintf("You have reached the %s session limit. Thanks for calling -- come back soon.",
+			components.FormatDuration(a.timeouts.Max)))
+	}
+	return timeoutTick()
+}
+
+// hangUp shows text for a moment, then ends the session.
+func (a *AppModel) hangUp(text string) tea.Cmd {
+	a.goodbye = text
+	return tea.Tick(2*time.Second, func(time.Time) tea.Msg { return tea.QuitMsg{} })
+}
+
+// idleLeft returns the time left before the idle timeout, or -1 if there
+// is none.
+func (a *AppModel) idleLeft() time.Duration {
+	if a.timeouts.Idle <= 0 {
+		return -1
+	}
+	return max(time.Until(a.lastKey.Add(a.timeouts.Idle)), 0)
+}
+
+// maxLeft returns the time left before the session limit, or -1 if there
+// is none.
+func (a *AppModel) maxLeft() time.Duration {
+	if a.timeouts.Max <= 0 {
+		return -1
+	}
+	return max(time.Until(a.started.Add(a.timeouts.Max)), 0)
+}
+
+// timeoutAlert returns the countdown for the status bar once a timeout is
+// less than timeoutWarning away.
+func (a *AppModel) timeoutAlert() string {
+	idle, limit := a.idleLeft(), a.maxLeft()
+	switch {
+	case limit >= 0 && limit < timeoutWarning && (idle < 0 || limit <= idle):
+		return fmt.Sprintf("session limit: hanging up in %s", limit.Round(time.Second))
+	case idle >= 0 && idle < timeoutWarning:
+		return fmt.Sprintf("idle: hanging up in %s -- press any key", idle.Round(time.Second))
+	}
+	return ""
+}
@@ -1,6 +1,10 @@
 package types
 
-import tea "github.com/charmbracelet/bubbletea"
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
 
 // Screen is implemented by all screen models.
 type Screen interface {
@@ -8,9 +12,28 @@ type Screen interface {
 	StatusInfo() (page string, volume *int)
 }
 
+// Sectioned is implemented by screens showing a document with sections,
+// so the status bar can say which section the reader is in.
+type Sectioned interface {
+	Section() string
+}
+
+// Finder is implemented by screens that can find text on the page, so the
+// status bar can show how a find is going.
+type Finder interface {
+	FindStatus() string // e.g. "match 3/17"; empty when nothing is being found
+}
+
+// BackgroundMsg is implemented by the results of work a screen does in
+// the background. They go to every screen on the stack, since the one
+// that started the work may have been covered by another meanwhile.
+type BackgroundMsg interface {
+	Background()
+}
+
 // NavigateMsg pushes a new screen onto the stack.
 type NavigateMsg struct {
-	Screen   string // "home", "volume", "article", "page", "help", "search"
+	Screen   string // "home", "volume", "article", "page", "help", "search", "doors", "callers", "polls", "scoreboard"
 	Volume   int
 	Article  int    // index in volume
 	PageSlug string // for static pages
@@ -26,3 +49,29 @@ type ReplaceMsg struct {
 	Volume  int
 	Article int
 }
+
+// BroadcastMsg carries a sysop message to every live session.
+type BroadcastMsg struct {
+	Text string
+}
+
+// ShutdownMsg warns a session that the server stops at At.
+type ShutdownMsg struct {
+	At time.Time
+}
+
+// KickMsg tells a session the sysop is disconnecting it.
+type KickMsg struct{}
+
+// QueueMsg updates a waiting caller's place in line.
+type QueueMsg struct {
+	Position int
+}
+
+// NodeReadyMsg tells a waiting caller a node is theirs.
+type NodeReadyMsg struct{}
+
+// ClipboardMsg asks the caller's terminal to put Text on the clipboard.
+type ClipboardMsg struct {
+	Text string
+}
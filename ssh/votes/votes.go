@@ -0,0 +1,176 @@
+package votes
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+var (
+	// ErrNoKey is returned when a guest without an SSH key tries to vote.
+	ErrNoKey = errors.New("connect with an SSH key to vote")
+
+	// ErrAlreadyVoted is returned on a second vote from the same key.
+	ErrAlreadyVoted = errors.New("you already voted in this poll")
+
+	// ErrBadOption is returned for an out-of-range option index.
+	ErrBadOption = errors.New("no such option")
+)
+
+// pollRecord is the persisted state of one poll. Votes are counted by
+// option text, so editing a poll's options cannot move votes from one to
+// another. Voters are stored as keyed hashes, see voterID, and which
+// option a voter picked is never recorded.
+type pollRecord struct {
+	Tally  map[string]int  `json:"tally"`
+	Voters map[string]bool `json:"voters"`
+}
+
+// booth is the persisted state of the voting booth.
+type booth struct {
+	Secret string                 `json:"secret"` // hex; keys the voter hashes
+	Polls  map[string]*pollRecord `json:"polls"`
+}
+
+// Booth holds the tallies for every poll and is shared by all sessions.
+type Booth struct {
+	path string
+
+	mu     sync.Mutex
+	secret []byte
+	polls  map[string]*pollRecord
+}
+
+// Open loads the tallies stored at path, starting empty, with a new
+// secret, if it does not exist.
+func Open(path string) (*Booth, error) {
+	b := &Booth{path: path, polls: make(map[string]*pollRecord)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		b.secret = make([]byte, 32)
+		if _, err := rand.Read(b.secret); err != nil {
+			return nil, err
+		}
+		return b, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var saved booth
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, err
+	}
+	b.secret, err = hex.DecodeString(saved.Secret)
+	if err != nil || len(b.secret) == 0 {
+		return nil, errors.New("missing or bad secret")
+	}
+	if saved.Polls != nil {
+		b.polls = saved.Polls
+	}
+	return b, nil
+}
+
+// voterID derives the stored voter identity for keyID in pollID. It is
+// keyed with the booth's secret, so that someone holding the tallies but
+// not the secret cannot test whether a known public key voted, and
+// differs from poll to poll, so ballots cannot be linked across polls.
+func (b *Booth) voterID(pollID, keyID string) string {
+	mac := hmac.New(sha256.New, b.secret)
+	mac.Write([]byte(pollID + "\x00" + keyID))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// record returns the record for pollID, creating it. Caller must hold b.mu.
+func (b *Booth) record(pollID string) *pollRecord {
+	r := b.polls[pollID]
+	if r == nil {
+		r = &pollRecord{Tally: make(map[string]int), Voters: make(map[string]bool)}
+		b.polls[pollID] = r
+	}
+	if r.Tally == nil {
+		r.Tally = make(map[string]int)
+	}
+	if r.Voters == nil {
+		r.Voters = make(map[string]bool)
+	}
+	return r
+}
+
+// Vote casts keyID's ballot for options[option] in pollID.
+func (b *Booth) Vote(pollID, keyID string, options []string, option int) error {
+	if keyID == "" {
+		return ErrNoKey
+	}
+	if option < 0 || option >= len(options) {
+		return ErrBadOption
+	}
+	choice := options[option]
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	r := b.record(pollID)
+	id := b.voterID(pollID, keyID)
+	if r.Voters[id] {
+		return ErrAlreadyVoted
+	}
+	r.Tally[choice]++
+	r.Voters[id] = true
+	if err := b.save(); err != nil {
+		if r.Tally[choice]--; r.Tally[choice] == 0 {
+			delete(r.Tally, choice)
+		}
+		delete(r.Voters, id)
+		return err
+	}
+	return nil
+}
+
+// HasVoted reports whether keyID already voted in pollID.
+func (b *Booth) HasVoted(pollID, keyID string) bool {
+	if keyID == "" {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	r := b.polls[pollID]
+	return r != nil && r.Voters[b.voterID(pollID, keyID)]
+}
+
+// Tally returns the vote count for each of a poll's options. Votes for
+// options since removed or reworded are left out.
+func (b *Booth) Tally(pollID string, options []string) []int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	out := make([]int, len(options))
+	if r := b.polls[pollID]; r != nil {
+		for i, opt := range options {
+			out[i] = r.Tally[opt]
+		}
+	}
+	return out
+}
+
+// save writes the tallies atomically. Caller must hold b.mu.
+func (b *Booth) save() error {
+	data, err := json.MarshalIndent(booth{Secret: hex.EncodeToString(b.secret), Polls: b.polls}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(b.path), 0o755); err != nil {
+		return err
+	}
+	tmp := b.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, b.path)
+}